@@ -1,14 +1,41 @@
 package settings
 
 import (
+	"crypto"
+	"crypto/x509"
+	"errors"
+	"log"
+	"os"
+	"regexp"
 	"time"
 )
 
+// Handle an error which caused a Dflt getter to return its default. In
+// StrictDflt mode errors other than a missing key, an out of range index or a
+// null value at `key` itself are passed to DfltHook, or logged with the
+// standard logger if it is not set. A missing field of an object is reported.
+func (s *Settings) dfltError(key string, err error) {
+	if !s.StrictDflt {
+		return
+	}
+	var pe *PathError
+	if errors.As(err, &pe) && pe.Key == s.FullKey(key) &&
+		(errors.Is(pe.Err, KeyError) || errors.Is(pe.Err, RangeError) || pe.Got == "null") {
+		return
+	}
+	if s.DfltHook == nil {
+		log.Print(err)
+	} else {
+		s.DfltHook(err)
+	}
+}
+
 // Get a value from the settings object. Return `dflt` if an error occurs.
 func (s *Settings) RawDflt(key string, dflt interface{}) interface{} {
 	if value, err := s.Raw(key); err == nil {
 		return value
 	} else {
+		s.dfltError(key, err)
 		return dflt
 	}
 }
@@ -18,6 +45,7 @@ func (s *Settings) ObjectDflt(key string, dflt *Settings) *Settings {
 	if value, err := s.Object(key); err == nil {
 		return value
 	} else {
+		s.dfltError(key, err)
 		return dflt
 	}
 }
@@ -27,6 +55,7 @@ func (s *Settings) ObjectArrayDflt(key string, dflt []*Settings) []*Settings {
 	if value, err := s.ObjectArray(key); err == nil {
 		return value
 	} else {
+		s.dfltError(key, err)
 		return dflt
 	}
 }
@@ -36,6 +65,7 @@ func (s *Settings) ObjectMapDflt(key string, dflt map[string]*Settings) map[stri
 	if value, err := s.ObjectMap(key); err == nil {
 		return value
 	} else {
+		s.dfltError(key, err)
 		return dflt
 	}
 }
@@ -45,6 +75,7 @@ func (s *Settings) StringDflt(key string, dflt string) string {
 	if value, err := s.String(key); err == nil {
 		return value
 	} else {
+		s.dfltError(key, err)
 		return dflt
 	}
 }
@@ -54,6 +85,7 @@ func (s *Settings) StringArrayDflt(key string, dflt []string) []string {
 	if value, err := s.StringArray(key); err == nil {
 		return value
 	} else {
+		s.dfltError(key, err)
 		return dflt
 	}
 }
@@ -63,6 +95,7 @@ func (s *Settings) StringMapDflt(key string, dflt map[string]string) map[string]
 	if value, err := s.StringMap(key); err == nil {
 		return value
 	} else {
+		s.dfltError(key, err)
 		return dflt
 	}
 }
@@ -72,6 +105,7 @@ func (s *Settings) IntDflt(key string, dflt int) int {
 	if value, err := s.Int(key); err == nil {
 		return value
 	} else {
+		s.dfltError(key, err)
 		return dflt
 	}
 }
@@ -81,6 +115,7 @@ func (s *Settings) IntArrayDflt(key string, dflt []int) []int {
 	if value, err := s.IntArray(key); err == nil {
 		return value
 	} else {
+		s.dfltError(key, err)
 		return dflt
 	}
 }
@@ -90,6 +125,7 @@ func (s *Settings) IntMapDflt(key string, dflt map[string]int) map[string]int {
 	if value, err := s.IntMap(key); err == nil {
 		return value
 	} else {
+		s.dfltError(key, err)
 		return dflt
 	}
 }
@@ -99,6 +135,7 @@ func (s *Settings) FloatDflt(key string, dflt float64) float64 {
 	if value, err := s.Float(key); err == nil {
 		return value
 	} else {
+		s.dfltError(key, err)
 		return dflt
 	}
 }
@@ -108,6 +145,7 @@ func (s *Settings) FloatArrayDflt(key string, dflt []float64) []float64 {
 	if value, err := s.FloatArray(key); err == nil {
 		return value
 	} else {
+		s.dfltError(key, err)
 		return dflt
 	}
 }
@@ -117,6 +155,7 @@ func (s *Settings) FloatMapDflt(key string, dflt map[string]float64) map[string]
 	if value, err := s.FloatMap(key); err == nil {
 		return value
 	} else {
+		s.dfltError(key, err)
 		return dflt
 	}
 }
@@ -126,6 +165,7 @@ func (s *Settings) BoolDflt(key string, dflt bool) bool {
 	if value, err := s.Bool(key); err == nil {
 		return value
 	} else {
+		s.dfltError(key, err)
 		return dflt
 	}
 }
@@ -135,6 +175,7 @@ func (s *Settings) BoolArrayDflt(key string, dflt []bool) []bool {
 	if value, err := s.BoolArray(key); err == nil {
 		return value
 	} else {
+		s.dfltError(key, err)
 		return dflt
 	}
 }
@@ -144,6 +185,7 @@ func (s *Settings) BoolMapDflt(key string, dflt map[string]bool) map[string]bool
 	if value, err := s.BoolMap(key); err == nil {
 		return value
 	} else {
+		s.dfltError(key, err)
 		return dflt
 	}
 }
@@ -153,6 +195,7 @@ func (s *Settings) DurationDflt(key string, dflt time.Duration) time.Duration {
 	if value, err := s.Duration(key); err == nil {
 		return value
 	} else {
+		s.dfltError(key, err)
 		return dflt
 	}
 }
@@ -162,6 +205,7 @@ func (s *Settings) DurationArrayDflt(key string, dflt []time.Duration) []time.Du
 	if value, err := s.DurationArray(key); err == nil {
 		return value
 	} else {
+		s.dfltError(key, err)
 		return dflt
 	}
 }
@@ -171,6 +215,7 @@ func (s *Settings) DurationMapDflt(key string, dflt map[string]time.Duration) ma
 	if value, err := s.DurationMap(key); err == nil {
 		return value
 	} else {
+		s.dfltError(key, err)
 		return dflt
 	}
 }
@@ -180,6 +225,7 @@ func (s *Settings) SizeDflt(key string, dflt int64) int64 {
 	if value, err := s.Size(key); err == nil {
 		return value
 	} else {
+		s.dfltError(key, err)
 		return dflt
 	}
 }
@@ -189,6 +235,7 @@ func (s *Settings) SizeArrayDflt(key string, dflt []int64) []int64 {
 	if value, err := s.SizeArray(key); err == nil {
 		return value
 	} else {
+		s.dfltError(key, err)
 		return dflt
 	}
 }
@@ -198,6 +245,219 @@ func (s *Settings) SizeMapDflt(key string, dflt map[string]int64) map[string]int
 	if value, err := s.SizeMap(key); err == nil {
 		return value
 	} else {
+		s.dfltError(key, err)
+		return dflt
+	}
+}
+
+// Get a regular expression value. Return `dflt` if an error occurs.
+func (s *Settings) RegexpDflt(key string, dflt *regexp.Regexp) *regexp.Regexp {
+	if value, err := s.Regexp(key); err == nil {
+		return value
+	} else {
+		s.dfltError(key, err)
+		return dflt
+	}
+}
+
+// Get an array of regular expression values. Return `dflt` if an error occurs.
+func (s *Settings) RegexpArrayDflt(key string, dflt []*regexp.Regexp) []*regexp.Regexp {
+	if value, err := s.RegexpArray(key); err == nil {
+		return value
+	} else {
+		s.dfltError(key, err)
+		return dflt
+	}
+}
+
+// Get a map of regular expression values. Return `dflt` if an error occurs.
+func (s *Settings) RegexpMapDflt(key string, dflt map[string]*regexp.Regexp) map[string]*regexp.Regexp {
+	if value, err := s.RegexpMap(key); err == nil {
+		return value
+	} else {
+		s.dfltError(key, err)
+		return dflt
+	}
+}
+
+// Get a string value matching one of the provided choices. Return `dflt` if an
+// error occurs.
+func (s *Settings) ChoiceDflt(key string, choices Choices, dflt string) string {
+	if value, err := s.Choice(key, choices); err == nil {
+		return value
+	} else {
+		s.dfltError(key, err)
+		return dflt
+	}
+}
+
+// Get a string value which must be one of the `allowed` values. Return `dflt`
+// if an error occurs.
+func (s *Settings) EnumDflt(key string, dflt string, allowed ...string) string {
+	if value, err := s.Enum(key, allowed...); err == nil {
+		return value
+	} else {
+		s.dfltError(key, err)
+		return dflt
+	}
+}
+
+// Get a filesystem path. Return `dflt` if an error occurs.
+func (s *Settings) PathDflt(key string, dflt string, checks ...PathCheck) string {
+	if value, err := s.Path(key, checks...); err == nil {
+		return value
+	} else {
+		s.dfltError(key, err)
+		return dflt
+	}
+}
+
+// Get an array of filesystem paths. Return `dflt` if an error occurs.
+func (s *Settings) PathArrayDflt(key string, dflt []string, checks ...PathCheck) []string {
+	if value, err := s.PathArray(key, checks...); err == nil {
+		return value
+	} else {
+		s.dfltError(key, err)
+		return dflt
+	}
+}
+
+// Get a file mode. Return `dflt` if an error occurs.
+func (s *Settings) FileModeDflt(key string, dflt os.FileMode) os.FileMode {
+	if value, err := s.FileMode(key); err == nil {
+		return value
+	} else {
+		s.dfltError(key, err)
+		return dflt
+	}
+}
+
+// Get a user ID. Return `dflt` if an error occurs.
+func (s *Settings) UserIDDflt(key string, dflt int) int {
+	if value, err := s.UserID(key); err == nil {
+		return value
+	} else {
+		s.dfltError(key, err)
+		return dflt
+	}
+}
+
+// Get a group ID. Return `dflt` if an error occurs.
+func (s *Settings) GroupIDDflt(key string, dflt int) int {
+	if value, err := s.GroupID(key); err == nil {
+		return value
+	} else {
+		s.dfltError(key, err)
+		return dflt
+	}
+}
+
+// Get a range of integers. Return `dflt` if an error occurs.
+func (s *Settings) RangeDflt(key string, dflt Range) Range {
+	if value, err := s.Range(key); err == nil {
+		return value
+	} else {
+		s.dfltError(key, err)
+		return dflt
+	}
+}
+
+// Get a range of ports. Return `dflt` if an error occurs.
+func (s *Settings) PortRangeDflt(key string, dflt Range) Range {
+	if value, err := s.PortRange(key); err == nil {
+		return value
+	} else {
+		s.dfltError(key, err)
+		return dflt
+	}
+}
+
+// Get a ratio. Return `dflt` if an error occurs.
+func (s *Settings) RatioDflt(key string, dflt float64) float64 {
+	if value, err := s.Ratio(key); err == nil {
+		return value
+	} else {
+		s.dfltError(key, err)
+		return dflt
+	}
+}
+
+// Get an array of ratios. Return `dflt` if an error occurs.
+func (s *Settings) RatioArrayDflt(key string, dflt []float64) []float64 {
+	if value, err := s.RatioArray(key); err == nil {
+		return value
+	} else {
+		s.dfltError(key, err)
+		return dflt
+	}
+}
+
+// Get a map of ratios. Return `dflt` if an error occurs.
+func (s *Settings) RatioMapDflt(key string, dflt map[string]float64) map[string]float64 {
+	if value, err := s.RatioMap(key); err == nil {
+		return value
+	} else {
+		s.dfltError(key, err)
+		return dflt
+	}
+}
+
+// Get binary data. Return `dflt` if an error occurs.
+func (s *Settings) BytesDflt(key string, dflt []byte) []byte {
+	if value, err := s.Bytes(key); err == nil {
+		return value
+	} else {
+		s.dfltError(key, err)
+		return dflt
+	}
+}
+
+// Get PEM encoded certificates. Return `dflt` if an error occurs.
+func (s *Settings) CertificatesDflt(key string, dflt []*x509.Certificate) []*x509.Certificate {
+	if value, err := s.Certificates(key); err == nil {
+		return value
+	} else {
+		s.dfltError(key, err)
+		return dflt
+	}
+}
+
+// Get a PEM encoded private key. Return `dflt` if an error occurs.
+func (s *Settings) PrivateKeyDflt(key string, dflt crypto.Signer) crypto.Signer {
+	if value, err := s.PrivateKey(key); err == nil {
+		return value
+	} else {
+		s.dfltError(key, err)
+		return dflt
+	}
+}
+
+// Get a time of day. Return `dflt` if an error occurs.
+func (s *Settings) TimeOfDayDflt(key string, dflt TimeOfDay) TimeOfDay {
+	if value, err := s.TimeOfDay(key); err == nil {
+		return value
+	} else {
+		s.dfltError(key, err)
+		return dflt
+	}
+}
+
+// Get a recurring window of time. Return `dflt` if an error occurs.
+func (s *Settings) WindowDflt(key string, dflt Window) Window {
+	if value, err := s.Window(key); err == nil {
+		return value
+	} else {
+		s.dfltError(key, err)
+		return dflt
+	}
+}
+
+// Get a schedule of recurring windows. Return `dflt` if an error occurs.
+func (s *Settings) ScheduleDflt(key string, dflt Schedule) Schedule {
+	if value, err := s.Schedule(key); err == nil {
+		return value
+	} else {
+		s.dfltError(key, err)
 		return dflt
 	}
 }
@@ -1,7 +1,15 @@
 package settings
 
 import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"errors"
+	"log"
+	"os"
 	"reflect"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 )
@@ -212,3 +220,273 @@ func TestSizeMapDflt(t *testing.T) {
 		t.Errorf("%v != %v", dflt, have)
 	}
 }
+
+func TestRegexpDflt(t *testing.T) {
+	settings := getSettings()
+	dflt := regexp.MustCompile("^.*$")
+	have := settings.RegexpDflt("nope", dflt)
+	if !reflect.DeepEqual(dflt, have) {
+		t.Errorf("%v != %v", dflt, have)
+	}
+}
+
+func TestRegexpArrayDflt(t *testing.T) {
+	settings := getSettings()
+	dflt := []*regexp.Regexp{regexp.MustCompile("^a"), regexp.MustCompile("b$")}
+	have := settings.RegexpArrayDflt("nope", dflt)
+	if !reflect.DeepEqual(dflt, have) {
+		t.Errorf("%v != %v", dflt, have)
+	}
+}
+
+func TestRegexpMapDflt(t *testing.T) {
+	settings := getSettings()
+	dflt := map[string]*regexp.Regexp{"a": regexp.MustCompile("^a")}
+	have := settings.RegexpMapDflt("nope", dflt)
+	if !reflect.DeepEqual(dflt, have) {
+		t.Errorf("%v != %v", dflt, have)
+	}
+}
+
+func TestChoiceDflt(t *testing.T) {
+	settings := getSettings()
+	dflt := "info"
+	have := settings.ChoiceDflt("values.string", Choices{Allowed: []string{"info", "debug"}}, dflt)
+	if !reflect.DeepEqual(dflt, have) {
+		t.Errorf("%v != %v", dflt, have)
+	}
+}
+
+func TestEnumDflt(t *testing.T) {
+	settings := getSettings()
+	dflt := "info"
+	have := settings.EnumDflt("nope", dflt, "info", "debug")
+	if !reflect.DeepEqual(dflt, have) {
+		t.Errorf("%v != %v", dflt, have)
+	}
+}
+
+func TestPathDflt(t *testing.T) {
+	settings := getSettings()
+	dflt := "/etc/ssl/cert.pem"
+	have := settings.PathDflt("nope", dflt)
+	if !reflect.DeepEqual(dflt, have) {
+		t.Errorf("%v != %v", dflt, have)
+	}
+}
+
+func TestPathArrayDflt(t *testing.T) {
+	settings := getSettings()
+	dflt := []string{"/etc/ssl/cert.pem", "/etc/ssl/key.pem"}
+	have := settings.PathArrayDflt("nope", dflt)
+	if !reflect.DeepEqual(dflt, have) {
+		t.Errorf("%v != %v", dflt, have)
+	}
+}
+
+func TestFileModeDflt(t *testing.T) {
+	settings := getSettings()
+	dflt := os.FileMode(0600)
+	have := settings.FileModeDflt("nope", dflt)
+	if !reflect.DeepEqual(dflt, have) {
+		t.Errorf("%v != %v", dflt, have)
+	}
+}
+
+func TestUserIDDflt(t *testing.T) {
+	settings := getSettings()
+	dflt := 65534
+	have := settings.UserIDDflt("nope", dflt)
+	if !reflect.DeepEqual(dflt, have) {
+		t.Errorf("%v != %v", dflt, have)
+	}
+}
+
+func TestGroupIDDflt(t *testing.T) {
+	settings := getSettings()
+	dflt := 65534
+	have := settings.GroupIDDflt("nope", dflt)
+	if !reflect.DeepEqual(dflt, have) {
+		t.Errorf("%v != %v", dflt, have)
+	}
+}
+
+func TestRangeDflt(t *testing.T) {
+	settings := getSettings()
+	dflt := Range{Min: 1, Max: 3}
+	have := settings.RangeDflt("nope", dflt)
+	if !reflect.DeepEqual(dflt, have) {
+		t.Errorf("%v != %v", dflt, have)
+	}
+}
+
+func TestPortRangeDflt(t *testing.T) {
+	settings := getSettings()
+	dflt := Range{Min: 8000, Max: 8100}
+	have := settings.PortRangeDflt("nope", dflt)
+	if !reflect.DeepEqual(dflt, have) {
+		t.Errorf("%v != %v", dflt, have)
+	}
+}
+
+func TestRatioDflt(t *testing.T) {
+	settings := getSettings()
+	dflt := 0.5
+	have := settings.RatioDflt("nope", dflt)
+	if !reflect.DeepEqual(dflt, have) {
+		t.Errorf("%v != %v", dflt, have)
+	}
+}
+
+func TestRatioArrayDflt(t *testing.T) {
+	settings := getSettings()
+	dflt := []float64{0.1, 0.9}
+	have := settings.RatioArrayDflt("nope", dflt)
+	if !reflect.DeepEqual(dflt, have) {
+		t.Errorf("%v != %v", dflt, have)
+	}
+}
+
+func TestRatioMapDflt(t *testing.T) {
+	settings := getSettings()
+	dflt := map[string]float64{"canary": 0.1}
+	have := settings.RatioMapDflt("nope", dflt)
+	if !reflect.DeepEqual(dflt, have) {
+		t.Errorf("%v != %v", dflt, have)
+	}
+}
+
+func TestBytesDflt(t *testing.T) {
+	settings := getSettings()
+	dflt := []byte("hello")
+	have := settings.BytesDflt("nope", dflt)
+	if !reflect.DeepEqual(dflt, have) {
+		t.Errorf("%v != %v", dflt, have)
+	}
+}
+
+func TestCertificatesDflt(t *testing.T) {
+	settings := getSettings()
+	dflt := []*x509.Certificate{}
+	have := settings.CertificatesDflt("nope", dflt)
+	if !reflect.DeepEqual(dflt, have) {
+		t.Errorf("%v != %v", dflt, have)
+	}
+}
+
+func TestPrivateKeyDflt(t *testing.T) {
+	settings := getSettings()
+	var dflt crypto.Signer = nil
+	have := settings.PrivateKeyDflt("nope", dflt)
+	if !reflect.DeepEqual(dflt, have) {
+		t.Errorf("%v != %v", dflt, have)
+	}
+}
+
+func TestTimeOfDayDflt(t *testing.T) {
+	settings := getSettings()
+	dflt := TimeOfDay{Hour: 2, Minute: 30}
+	have := settings.TimeOfDayDflt("nope", dflt)
+	if !reflect.DeepEqual(dflt, have) {
+		t.Errorf("%v != %v", dflt, have)
+	}
+}
+
+func TestWindowDflt(t *testing.T) {
+	settings := getSettings()
+	dflt := Window{From: TimeOfDay{Hour: 1}, To: TimeOfDay{Hour: 5}}
+	have := settings.WindowDflt("nope", dflt)
+	if !reflect.DeepEqual(dflt, have) {
+		t.Errorf("%v != %v", dflt, have)
+	}
+}
+
+func TestScheduleDflt(t *testing.T) {
+	settings := getSettings()
+	dflt := Schedule{{From: TimeOfDay{Hour: 1}, To: TimeOfDay{Hour: 5}}}
+	have := settings.ScheduleDflt("nope", dflt)
+	if !reflect.DeepEqual(dflt, have) {
+		t.Errorf("%v != %v", dflt, have)
+	}
+}
+
+func TestStrictDflt(t *testing.T) {
+	settings, _ := Parse([]byte(`port: eighty
+timeout: ~
+ports: [80, ~]
+db: {host: localhost}
+range: {max: 10}
+window: {to: "17:00"}`))
+	var errs []error
+	settings.StrictDflt = true
+	settings.DfltHook = func(err error) {
+		errs = append(errs, err)
+	}
+
+	// missing and null values use the default
+	if have := settings.IntDflt("missing", 8080); have != 8080 {
+		t.Errorf("%v != %v", 8080, have)
+	}
+	if have := settings.DurationDflt("timeout", time.Second); have != time.Second {
+		t.Errorf("%v != %v", time.Second, have)
+	}
+	if have := settings.IntDflt("ports.5", 80); have != 80 {
+		t.Errorf("%v != %v", 80, have)
+	}
+	if len(errs) != 0 {
+		t.Errorf("missing values reported: %v", errs)
+	}
+
+	// other errors are reported
+	if have := settings.IntDflt("port", 8080); have != 8080 {
+		t.Errorf("%v != %v", 8080, have)
+	}
+	if have := settings.IntArrayDflt("ports", nil); have != nil {
+		t.Errorf("%v != %v", nil, have)
+	}
+	db, _ := settings.Object("db")
+	if have := db.IntDflt("host", 5432); have != 5432 {
+		t.Errorf("%v != %v", 5432, have)
+	}
+	dfltRange := Range{Min: 1, Max: 2}
+	if have := settings.RangeDflt("range", dfltRange); have != dfltRange {
+		t.Errorf("%v != %v", dfltRange, have)
+	}
+	if have := settings.WindowDflt("window", Window{}); !reflect.DeepEqual(have, Window{}) {
+		t.Errorf("%v != %v", Window{}, have)
+	}
+	want := []struct {
+		key string
+		err error
+	}{
+		{"port", TypeError},
+		{"ports.1", TypeError},
+		{"db.host", TypeError},
+		{"range.min", KeyError},
+		{"window.from", KeyError},
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("%d errors reported instead of %d: %v", len(errs), len(want), errs)
+	}
+	for n, w := range want {
+		var pe *PathError
+		if !errors.As(errs[n], &pe) || pe.Key != w.key || !errors.Is(pe, w.err) {
+			t.Errorf("error %v is not a %v for %s", errs[n], w.err, w.key)
+		}
+	}
+
+	// errors are logged without a hook
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+	settings.DfltHook = nil
+	if have := settings.IntDflt("port", 8080); have != 8080 {
+		t.Errorf("%v != %v", 8080, have)
+	}
+	if !strings.Contains(buf.String(), `port: cannot convert "eighty" to int`) {
+		t.Errorf("error not logged: %q", buf.String())
+	}
+	if have := settings.StringDflt("missing", "none"); have != "none" {
+		t.Errorf("%v != %v", "none", have)
+	}
+}
@@ -0,0 +1,231 @@
+package settings
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+)
+
+const (
+	day  = 24 * time.Hour
+	week = 7 * day
+)
+
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"µs": time.Microsecond,
+	"μs": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  day,
+	"w":  week,
+}
+
+var isoDateUnits = map[byte]time.Duration{
+	'W': week,
+	'D': day,
+}
+
+var isoTimeUnits = map[byte]time.Duration{
+	'H': time.Hour,
+	'M': time.Minute,
+	'S': time.Second,
+}
+
+// Parse a duration using seconds as the unit of bare numbers.
+func ParseDuration(s string) (time.Duration, error) {
+	return ParseDurationUnit(s, time.Second)
+}
+
+// Parse a duration. Three formats are accepted:
+//
+//   - Go durations as accepted by time.ParseDuration extended with `d` (days)
+//     and `w` (weeks) units, e.g. `1w2d`, `36h` or `1.5d`.
+//   - ISO 8601 durations without years or months, e.g. `P1DT2H` or `PT30S`.
+//   - Bare numbers, which are multiplied by `unit`.
+//
+// A day is always 24 hours.
+func ParseDurationUnit(s string, unit time.Duration) (time.Duration, error) {
+	str := strings.TrimSpace(s)
+	sign := ""
+	if strings.HasPrefix(str, "-") || strings.HasPrefix(str, "+") {
+		sign, str = str[:1], str[1:]
+	}
+
+	var total *big.Rat
+	var err error
+	if str == "" {
+		err = fmt.Errorf("invalid duration %q", s)
+	} else if str[0] == 'P' || str[0] == 'p' {
+		total, err = parseISODuration(str)
+	} else if strings.Trim(str, "0123456789.") == "" {
+		total, err = getDurationPart(str, unit)
+	} else {
+		total, err = parseDurationParts(str)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q: %w", s, err)
+	}
+
+	if sign == "-" {
+		total.Neg(total)
+	}
+	return getDurationFromRat(s, total)
+}
+
+// Multiply a decimal number by a unit.
+func getDurationPart(number string, unit time.Duration) (*big.Rat, error) {
+	if number == "" || number == "." {
+		return nil, fmt.Errorf("missing number")
+	}
+	value, ok := new(big.Rat).SetString(number)
+	if !ok || strings.ContainsAny(number, "+-eE/") {
+		return nil, fmt.Errorf("invalid number %q", number)
+	}
+	return value.Mul(value, new(big.Rat).SetInt64(int64(unit))), nil
+}
+
+// Convert a number of nanoseconds to a duration, rounding to the nearest
+// nanosecond.
+func getDurationFromRat(s string, value *big.Rat) (time.Duration, error) {
+	half := big.NewRat(1, 2)
+	if value.Sign() < 0 {
+		half.Neg(half)
+	}
+	value.Add(value, half)
+	n := new(big.Int).Quo(value.Num(), value.Denom())
+	if !n.IsInt64() {
+		return 0, fmt.Errorf("duration %q overflows time.Duration", s)
+	}
+	return time.Duration(n.Int64()), nil
+}
+
+// Parse a sequence of numbers and units such as `1w2d3h`.
+func parseDurationParts(s string) (*big.Rat, error) {
+	total := new(big.Rat)
+	for s != "" {
+		end := strings.IndexFunc(s, func(r rune) bool {
+			return !strings.ContainsRune("0123456789.", r)
+		})
+		if end <= 0 {
+			return nil, fmt.Errorf("missing number")
+		}
+		number := s[:end]
+		s = s[end:]
+
+		end = strings.IndexAny(s, "0123456789.")
+		if end < 0 {
+			end = len(s)
+		}
+		name := strings.ToLower(s[:end])
+		s = s[end:]
+
+		unit, ok := durationUnits[name]
+		if !ok {
+			if name == "" {
+				return nil, fmt.Errorf("missing unit")
+			}
+			return nil, fmt.Errorf("unknown unit %q", name)
+		}
+		part, err := getDurationPart(number, unit)
+		if err != nil {
+			return nil, err
+		}
+		total.Add(total, part)
+	}
+	return total, nil
+}
+
+// Parse an ISO 8601 duration such as `P1DT2H30M`. Years and months are
+// rejected as they do not have a fixed length.
+func parseISODuration(s string) (*big.Rat, error) {
+	s = strings.ToUpper(s[1:])
+	date, clock := s, ""
+	if n := strings.IndexByte(s, 'T'); n >= 0 {
+		date, clock = s[:n], s[n+1:]
+		if clock == "" {
+			return nil, fmt.Errorf("missing time components")
+		}
+	}
+	if date == "" && clock == "" {
+		return nil, fmt.Errorf("missing components")
+	}
+
+	total := new(big.Rat)
+	for _, part := range []struct {
+		str   string
+		units map[byte]time.Duration
+	}{{date, isoDateUnits}, {clock, isoTimeUnits}} {
+		str := part.str
+		for str != "" {
+			end := strings.IndexFunc(str, func(r rune) bool {
+				return !strings.ContainsRune("0123456789.,", r)
+			})
+			if end < 0 {
+				return nil, fmt.Errorf("missing designator")
+			}
+			designator := str[end]
+			unit, ok := part.units[designator]
+			if !ok {
+				if designator == 'Y' || designator == 'M' {
+					return nil, fmt.Errorf("years and months are not supported")
+				}
+				return nil, fmt.Errorf("unknown designator %q", designator)
+			}
+			value, err := getDurationPart(strings.Replace(str[:end], ",", ".", 1), unit)
+			if err != nil {
+				return nil, err
+			}
+			total.Add(total, value)
+			str = str[end+1:]
+		}
+	}
+	return total, nil
+}
+
+// Format a duration using the units accepted by ParseDuration. The result is
+// exact, e.g. `1w2d`, `36h` is formatted as `1d12h` and `1500ms` as `1s500ms`.
+func FormatDuration(d time.Duration) string {
+	if d == 0 {
+		return "0s"
+	}
+
+	var b strings.Builder
+	var n uint64
+	if d < 0 {
+		b.WriteByte('-')
+		if d == math.MinInt64 {
+			n = 1 << 63
+		} else {
+			n = uint64(-d)
+		}
+	} else {
+		n = uint64(d)
+	}
+
+	units := []struct {
+		symbol string
+		unit   time.Duration
+	}{
+		{"w", week},
+		{"d", day},
+		{"h", time.Hour},
+		{"m", time.Minute},
+		{"s", time.Second},
+		{"ms", time.Millisecond},
+		{"us", time.Microsecond},
+		{"ns", time.Nanosecond},
+	}
+	for _, unit := range units {
+		if count := n / uint64(unit.unit); count > 0 {
+			fmt.Fprintf(&b, "%d%s", count, unit.symbol)
+			n -= count * uint64(unit.unit)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,117 @@
+package settings
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	type test struct {
+		str      string
+		duration time.Duration
+	}
+
+	tests := []test{
+		// go durations
+		{"5m", 5 * time.Minute},
+		{"1h30m", 90 * time.Minute},
+		{"1.5h", 90 * time.Minute},
+		{"300ms", 300 * time.Millisecond},
+		{"2us", 2 * time.Microsecond},
+		{"-10s", -10 * time.Second},
+
+		// days and weeks
+		{"7d", 7 * day},
+		{"2w", 2 * week},
+		{"1w2d3h", week + 2*day + 3*time.Hour},
+		{"1.5d", 36 * time.Hour},
+		{" 7D ", 7 * day},
+
+		// iso 8601
+		{"P1DT2H", day + 2*time.Hour},
+		{"PT30S", 30 * time.Second},
+		{"PT1.5M", 90 * time.Second},
+		{"PT0,5S", 500 * time.Millisecond},
+		{"P2W", 2 * week},
+		{"-P1D", -day},
+
+		// bare numbers
+		{"30", 30 * time.Second},
+		{"1.5", 1500 * time.Millisecond},
+		{"0", 0},
+	}
+
+	for _, test := range tests {
+		if d, err := ParseDuration(test.str); err != nil {
+			t.Errorf("error parsing %s: %s", test.str, err)
+		} else if d != test.duration {
+			t.Errorf("duration is invalid: %v != %v", d, test.duration)
+		}
+	}
+
+	errors := []string{
+		"",
+		"-",
+		"d",
+		"5x",
+		"5 minutes",
+		"1..5s",
+		"1e3s",
+		"P",
+		"P1DT",
+		"P1Y",
+		"P1M",
+		"PT1X",
+		"PT5",
+		"1000000w",
+	}
+
+	for _, str := range errors {
+		if _, err := ParseDuration(str); err == nil {
+			t.Errorf("no error parsing %s", str)
+		}
+	}
+}
+
+func TestParseDurationUnit(t *testing.T) {
+	if d, err := ParseDurationUnit("90", time.Minute); err != nil {
+		t.Error(err)
+	} else if d != 90*time.Minute {
+		t.Errorf("duration is invalid: %v != %v", d, 90*time.Minute)
+	}
+
+	if d, err := ParseDurationUnit("7d", time.Minute); err != nil {
+		t.Error(err)
+	} else if d != 7*day {
+		t.Errorf("duration is invalid: %v != %v", d, 7*day)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	type test struct {
+		duration time.Duration
+		str      string
+	}
+
+	tests := []test{
+		{0, "0s"},
+		{time.Second, "1s"},
+		{36 * time.Hour, "1d12h"},
+		{week + 2*day, "1w2d"},
+		{1500 * time.Millisecond, "1s500ms"},
+		{-90 * time.Minute, "-1h30m"},
+		{time.Duration(1<<63 - 1), "15250w1d23h47m16s854ms775us807ns"},
+	}
+
+	for _, test := range tests {
+		str := FormatDuration(test.duration)
+		if str != test.str {
+			t.Errorf("format is invalid: %s != %s", str, test.str)
+		}
+		if d, err := ParseDuration(str); err != nil {
+			t.Errorf("error parsing %s: %s", str, err)
+		} else if d != test.duration {
+			t.Errorf("duration is invalid: %v != %v", d, test.duration)
+		}
+	}
+}
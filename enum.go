@@ -0,0 +1,54 @@
+package settings
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Choices describes the values allowed for an enumerated setting.
+type Choices struct {
+	// The canonical values which are allowed.
+	Allowed []string
+	// Alternate spellings mapped to one of the allowed values.
+	Aliases map[string]string
+	// Match values and aliases without regard to case.
+	IgnoreCase bool
+}
+
+// EnumError is returned when a value is not one of the allowed choices.
+type EnumError struct {
+	Value   string
+	Allowed []string
+}
+
+func (e *EnumError) Error() string {
+	return fmt.Sprintf("invalid value %q, must be one of: %s", e.Value, strings.Join(e.Allowed, ", "))
+}
+
+// Compare two strings according to the case sensitivity of the choices.
+func (c Choices) equal(a, b string) bool {
+	if c.IgnoreCase {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+// Match returns the canonical allowed value for `value`. Aliases are resolved
+// to the value they map to. An error is returned if there is no match.
+func (c Choices) Match(value string) (string, error) {
+	for _, allowed := range c.Allowed {
+		if c.equal(allowed, value) {
+			return allowed, nil
+		}
+	}
+	for alias, target := range c.Aliases {
+		if c.equal(alias, value) {
+			for _, allowed := range c.Allowed {
+				if allowed == target {
+					return allowed, nil
+				}
+			}
+		}
+	}
+	return "", &EnumError{Value: value, Allowed: c.Allowed}
+}
@@ -0,0 +1,62 @@
+package settings
+
+import (
+	"testing"
+)
+
+func TestChoicesMatch(t *testing.T) {
+	type test struct {
+		value string
+		want  string
+	}
+
+	choices := Choices{
+		Allowed: []string{"debug", "info", "warn"},
+		Aliases: map[string]string{"warning": "warn", "broken": "error"},
+	}
+	folded := choices
+	folded.IgnoreCase = true
+
+	tests := []test{
+		{"debug", "debug"},
+		{"warn", "warn"},
+		{"warning", "warn"},
+	}
+	for _, test := range tests {
+		if have, err := choices.Match(test.value); err != nil {
+			t.Errorf("error matching %s: %s", test.value, err)
+		} else if have != test.want {
+			t.Errorf("%s != %s", test.want, have)
+		}
+	}
+
+	foldedTests := []test{
+		{"DEBUG", "debug"},
+		{"Info", "info"},
+		{"WARNING", "warn"},
+	}
+	for _, test := range foldedTests {
+		if have, err := folded.Match(test.value); err != nil {
+			t.Errorf("error matching %s: %s", test.value, err)
+		} else if have != test.want {
+			t.Errorf("%s != %s", test.want, have)
+		}
+	}
+
+	errors := []string{"DEBUG", "debgu", "broken", ""}
+	for _, value := range errors {
+		if _, err := choices.Match(value); err == nil {
+			t.Errorf("no error matching %s", value)
+		} else if _, ok := err.(*EnumError); !ok {
+			t.Errorf("error matching %s is not an EnumError: %s", value, err)
+		}
+	}
+}
+
+func TestEnumError(t *testing.T) {
+	err := &EnumError{Value: "debgu", Allowed: []string{"debug", "info"}}
+	want := `invalid value "debgu", must be one of: debug, info`
+	if have := err.Error(); have != want {
+		t.Errorf("%s != %s", want, have)
+	}
+}
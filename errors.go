@@ -1,9 +1,109 @@
 package settings
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
+var ConflictError error = errors.New("conflicting keys")
 var IndexError error = errors.New("invalid index")
 var KeyError error = errors.New("key not found")
 var ObjectError error = errors.New("invalid object")
 var RangeError error = errors.New("index out of range")
+var SkipTree error = errors.New("skip this tree")
+var SyntaxError error = errors.New("invalid key syntax")
 var TypeError error = errors.New("invalid type conversion")
+
+// PathError records an error and the key of the value which caused it. Every
+// getter and setter returns its errors as a *PathError which wraps one of the
+// errors above or a parse error, so `errors.Is(err, KeyError)` may be used to
+// test for a missing key.
+type PathError struct {
+	// Op is the operation which failed: "get", "set", "append" or "delete".
+	Op string
+	// Key is the absolute key of the operation.
+	Key string
+	// Segment is the segment of the key at which the error occurred if known.
+	Segment string
+	// Want is the type which was wanted by a failed type conversion.
+	Want string
+	// Got is the type of the value which could not be converted.
+	Got string
+	// Value is the scalar value which could not be converted if known.
+	Value string
+	// Position is the location of the value in its source if known.
+	Position Position
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *PathError) Error() string {
+	msg := e.Key + ": "
+	if e.Value != "" {
+		msg += fmt.Sprintf("cannot convert %q to %s", e.Value, e.Want)
+	} else if e.Want != "" {
+		msg += fmt.Sprintf("%s: want %s, got %s", e.Err, e.Want, e.Got)
+	} else {
+		msg += e.Err.Error()
+	}
+	if e.Position.IsValid() {
+		msg = e.Position.String() + ": " + msg
+	}
+	return msg
+}
+
+// Unwrap returns the underlying error.
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+// Return the name of the type of a value as used in error messages.
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[interface{}]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case int, int64, uint64:
+		return "int"
+	case float64:
+		return "float"
+	case bool:
+		return "bool"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// Return a TypeError for a value which could not be converted to `want`.
+// Scalar values are recorded so they may be quoted in the message.
+func typeError(want string, value interface{}) error {
+	err := &PathError{Want: want, Got: typeName(value), Err: TypeError}
+	switch value.(type) {
+	case string, int, int64, uint64, float64, bool:
+		err.Value = fmt.Sprint(value)
+	}
+	return err
+}
+
+// Return an ObjectError for a value which is not an object or array.
+func objectError(value interface{}) error {
+	return &PathError{Want: "object or array", Got: typeName(value), Err: ObjectError}
+}
+
+// Return an error which occurred at a key segment.
+func segmentError(segment string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if pe, ok := err.(*PathError); ok {
+		if pe.Segment == "" {
+			pe.Segment = segment
+		}
+		return pe
+	}
+	return &PathError{Segment: segment, Err: err}
+}
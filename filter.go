@@ -0,0 +1,112 @@
+package settings
+
+import (
+	"fmt"
+	"strings"
+)
+
+// filter selects the elements of an array of objects whose field compares
+// equal (or not equal) to a value. A filter selects only the first matching
+// element unless `all` is set.
+type filter struct {
+	field  string
+	value  string
+	negate bool
+	all    bool
+}
+
+// Parse a filter expression without its surrounding brackets. Expressions have
+// the form `field=value`, `field==value` or `field!=value` and may be prefixed
+// with `?` to select every matching element. The field and value may be
+// wrapped in double quotes.
+func parseFilter(expr string) (*filter, error) {
+	f := &filter{}
+	if strings.HasPrefix(expr, "?") {
+		f.all = true
+		expr = expr[1:]
+	}
+
+	field, rest, err := parseFilterOperand(expr, true)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case strings.HasPrefix(rest, "!="):
+		f.negate = true
+		rest = rest[2:]
+	case strings.HasPrefix(rest, "=="):
+		rest = rest[2:]
+	case strings.HasPrefix(rest, "="):
+		rest = rest[1:]
+	default:
+		return nil, SyntaxError
+	}
+
+	value, rest, err := parseFilterOperand(rest, false)
+	if err != nil || rest != "" || field == "" {
+		return nil, SyntaxError
+	}
+	f.field, f.value = field, value
+	return f, nil
+}
+
+// Parse one side of a filter expression. Return the operand and the remainder
+// of the expression.
+func parseFilterOperand(expr string, field bool) (string, string, error) {
+	expr = strings.TrimLeft(expr, " ")
+	if !strings.HasPrefix(expr, `"`) {
+		end := len(expr)
+		if field {
+			if end = strings.IndexAny(expr, "=!"); end == -1 {
+				return "", "", SyntaxError
+			}
+		}
+		return strings.TrimSpace(expr[:end]), expr[end:], nil
+	}
+
+	var b strings.Builder
+	for i := 1; i < len(expr); i++ {
+		switch c := expr[i]; c {
+		case '\\':
+			if i+1 >= len(expr) {
+				return "", "", SyntaxError
+			}
+			i++
+			b.WriteByte(expr[i])
+		case '"':
+			return b.String(), strings.TrimLeft(expr[i+1:], " "), nil
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", "", SyntaxError
+}
+
+// Return true if the element matches the filter. Fields are matched with the
+// key matching rules of the settings object and values are compared by their
+// string form. A missing field compares equal to `null`.
+func (f *filter) match(s *Settings, element interface{}) bool {
+	mapping, ok := element.(map[interface{}]interface{})
+	if !ok {
+		return false
+	}
+	value := "null"
+	if field, err := s.mapValue(mapping, f.field); err == nil && field != nil {
+		value = fmt.Sprint(field)
+	}
+	return (value == f.value) != f.negate
+}
+
+// Return the indices of the elements of the array selected by the filter.
+func (f *filter) selectIndices(s *Settings, items []interface{}) []int {
+	var indices []int
+	for n, item := range items {
+		if f.match(s, item) {
+			indices = append(indices, n)
+			if !f.all {
+				break
+			}
+		}
+	}
+	return indices
+}
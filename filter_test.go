@@ -0,0 +1,194 @@
+package settings
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func getFilterSettings() *Settings {
+	settings, _ := Parse([]byte(`backends:
+- name: primary
+  url: http://primary:9000
+  weight: 10
+- name: secondary
+  url: http://secondary:9000
+- name: "a.b"
+  url: http://ab:9000
+users:
+- name: alice
+  enabled: true
+- name: bob
+  enabled: false
+- name: carol
+  enabled: true
+scalar: value
+mapping:
+  name: primary`))
+	return settings
+}
+
+func TestParseFilter(t *testing.T) {
+	type test struct {
+		expr   string
+		filter filter
+	}
+
+	tests := []test{
+		{"name=primary", filter{field: "name", value: "primary"}},
+		{"name==primary", filter{field: "name", value: "primary"}},
+		{"name!=primary", filter{field: "name", value: "primary", negate: true}},
+		{"?enabled==true", filter{field: "enabled", value: "true", all: true}},
+		{" name = primary ", filter{field: "name", value: "primary"}},
+		{`name="a=b]"`, filter{field: "name", value: "a=b]"}},
+		{`"a=b"=c`, filter{field: "a=b", value: "c"}},
+		{`name=""`, filter{field: "name", value: ""}},
+		{`name="a\"b"`, filter{field: "name", value: `a"b`}},
+	}
+
+	for _, test := range tests {
+		if f, err := parseFilter(test.expr); err != nil {
+			t.Errorf("error parsing %s: %s", test.expr, err)
+		} else if !reflect.DeepEqual(*f, test.filter) {
+			t.Errorf("%s: %#v != %#v", test.expr, *f, test.filter)
+		}
+	}
+
+	errors := []string{"", "name", "=primary", "?", "name<primary", `name="primary`, `name="a"b`}
+	for _, expr := range errors {
+		if _, err := parseFilter(expr); err != SyntaxError {
+			t.Errorf("no error parsing %s", expr)
+		}
+	}
+}
+
+func TestFilterKeys(t *testing.T) {
+	valid := []string{
+		"backends[name=primary].url",
+		`"backends"[name=primary].url`,
+		"backends[name=primary][url=x]",
+		`backends[name="a.b"].url`,
+		"users[?enabled==true].name",
+	}
+	for _, key := range valid {
+		if _, err := parseKeySegments(key); err != nil {
+			t.Errorf("error parsing %s: %s", key, err)
+		}
+		if _, err := ParseKey(key); err != SyntaxError {
+			t.Errorf("ParseKey accepted filter %s", key)
+		}
+	}
+
+	invalid := []string{"[name=primary]", "a.[name=primary]", "a[name=primary", "a[name=primary]b", "a[name]"}
+	for _, key := range invalid {
+		if _, err := parseKeySegments(key); err != SyntaxError {
+			t.Errorf("no error parsing %s", key)
+		}
+	}
+}
+
+func TestFilterGet(t *testing.T) {
+	settings := getFilterSettings()
+
+	strings := map[string]string{
+		"backends[name=primary].url":  "http://primary:9000",
+		"backends[name!=primary].url": "http://secondary:9000",
+		`backends[name="a.b"].url`:    "http://ab:9000",
+		"backends[weight=10].name":    "primary",
+		"users[enabled=false].name":   "bob",
+	}
+	for key, want := range strings {
+		if value, err := settings.String(key); err != nil {
+			t.Errorf("%s: %s", key, err)
+		} else if value != want {
+			t.Errorf("%s: %s != %s", key, value, want)
+		}
+	}
+
+	if _, err := settings.String("backends[name=missing].url"); !errors.Is(err, KeyError) {
+		t.Errorf("missing element found: %v", err)
+	}
+	if _, err := settings.String("scalar[name=primary]"); !errors.Is(err, TypeError) {
+		t.Errorf("scalar filtered: %v", err)
+	}
+	if _, err := settings.String("mapping[name=primary]"); !errors.Is(err, TypeError) {
+		t.Errorf("mapping filtered: %v", err)
+	}
+
+	if value, err := settings.StringArray("users[?enabled==true].name"); err != nil {
+		t.Error(err)
+	} else if want := []string{"alice", "carol"}; !reflect.DeepEqual(value, want) {
+		t.Errorf("%v != %v", value, want)
+	}
+	if value, err := settings.Raw("users[?enabled==maybe]"); err != nil || len(value.([]interface{})) != 0 {
+		t.Errorf("invalid empty result: %v (%v)", value, err)
+	}
+	if _, err := settings.Raw("missing[?enabled==true]"); !errors.Is(err, KeyError) {
+		t.Errorf("missing array found: %v", err)
+	}
+	if _, err := settings.Raw("scalar[?enabled==true]"); !errors.Is(err, TypeError) {
+		t.Errorf("scalar filtered: %v", err)
+	}
+
+	if objects, err := settings.ObjectArray("users[?enabled==true]"); err != nil {
+		t.Error(err)
+	} else if len(objects) != 2 || objects[0].Key != "users.0" || objects[1].Key != "users.2" {
+		t.Errorf("invalid objects: %v", objects)
+	}
+	if object, err := settings.Object("backends[name=secondary]"); err != nil {
+		t.Error(err)
+	} else if value, _ := object.String("url"); value != "http://secondary:9000" {
+		t.Errorf("invalid object url %s", value)
+	}
+}
+
+func TestFilterSet(t *testing.T) {
+	settings := getFilterSettings()
+
+	if err := settings.Set("backends[name=primary].url", "http://new:9000"); err != nil {
+		t.Error(err)
+	}
+	if value, _ := settings.String("backends.0.url"); value != "http://new:9000" {
+		t.Errorf("url not set: %s", value)
+	}
+
+	if err := settings.Set("backends[name=secondary].tls.enabled", true); err != nil {
+		t.Error(err)
+	}
+	if value, _ := settings.Bool("backends.1.tls.enabled"); !value {
+		t.Error("nested value not set")
+	}
+
+	if err := settings.Set("users[?enabled==true].admin", true); err != nil {
+		t.Error(err)
+	}
+	if value, _ := settings.BoolArray("users[?admin==true].enabled"); !reflect.DeepEqual(value, []bool{true, true}) {
+		t.Errorf("invalid admins: %v", value)
+	}
+	if settings.Has("users.1.admin") {
+		t.Error("disabled user modified")
+	}
+
+	if err := settings.Set("backends[name=a.b]", map[string]interface{}{"name": "c"}); err != nil {
+		t.Error(err)
+	}
+	if value, _ := settings.String("backends.2.name"); value != "c" {
+		t.Errorf("element not replaced: %s", value)
+	}
+
+	if err := settings.Set("backends[name=missing].url", "x"); !errors.Is(err, KeyError) {
+		t.Errorf("missing element set: %v", err)
+	}
+	if err := settings.Set("mapping[name=primary].url", "x"); !errors.Is(err, TypeError) {
+		t.Errorf("mapping filtered: %v", err)
+	}
+	if err := settings.Set("users[?name==missing].admin", true); err != nil {
+		t.Errorf("empty selection failed: %v", err)
+	}
+	if err := settings.Append("backends[name=primary].tags", "x"); !errors.Is(err, SyntaxError) {
+		t.Errorf("filter appended: %v", err)
+	}
+	if err := settings.Delete("backends[name=primary].url"); !errors.Is(err, SyntaxError) {
+		t.Errorf("filter deleted: %v", err)
+	}
+}
@@ -1,38 +1,215 @@
 package settings
 
 import (
+	"crypto"
+	"crypto/x509"
 	"fmt"
+	"gopkg.in/yaml.v2"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
 )
 
-// Get a value from the settings object.
+// Get a value from the settings object. See ParseKey for the key syntax. A key
+// containing a `[?...]` filter is evaluated as a query and returns an array of
+// the matching values.
 func (s *Settings) Raw(key string) (interface{}, error) {
-	names := strings.Split(key, ".")
-	var data interface{} = s.Values
-	for _, name := range names {
-		if items, ok := data.(map[interface{}]interface{}); ok {
-			if data, ok = items[name]; !ok {
-				return nil, KeyError
-			}
-		} else if items, ok := data.([]interface{}); ok {
-			if n, err := strconv.Atoi(name); err == nil {
-				if n < len(items) {
-					data = items[n]
-				} else {
-					return nil, KeyError
-				}
-			} else {
-				return nil, KeyError
+	if path, err := s.parseKey(key); err == nil {
+		if projects(path) {
+			matches, err := s.project(path)
+			if err != nil {
+				return nil, s.keyError(key, err)
+			}
+			values := make([]interface{}, len(matches))
+			for n, match := range matches {
+				values[n] = match.Value
 			}
+			return values, nil
+		}
+		value, err := s.rawPath(path)
+		return value, s.keyError(key, err)
+	} else {
+		return nil, s.keyError(key, err)
+	}
+}
+
+// Get the value at the provided path. Errors record the segment at which the
+// lookup failed.
+func (s *Settings) rawPath(path []segment) (interface{}, error) {
+	var data interface{} = s.Values
+	for _, seg := range path {
+		if child, err := s.getChild(data, seg); err == nil {
+			data = child
 		} else {
-			return nil, TypeError
+			return nil, segmentError(seg.name, err)
 		}
 	}
 	return data, nil
 }
 
+// Get the child of an object or array identified by a single key segment.
+func (s *Settings) getChild(data interface{}, seg segment) (interface{}, error) {
+	if items, ok := data.(map[interface{}]interface{}); ok {
+		if seg.filter != nil {
+			return nil, typeError("array", data)
+		}
+		return s.mapValue(items, seg.name)
+	} else if items, ok := data.([]interface{}); ok {
+		if seg.filter != nil {
+			if indices := seg.filter.selectIndices(s, items); len(indices) != 0 {
+				return items[indices[0]], nil
+			}
+			return nil, KeyError
+		} else if start, end, ok, err := arraySlice(seg.name, len(items)); ok {
+			if err != nil {
+				return nil, err
+			}
+			return items[start:end:end], nil
+		} else if n, err := arrayIndex(seg.name, len(items)); err == nil {
+			return items[n], nil
+		} else {
+			return nil, err
+		}
+	}
+	return nil, typeError("object", data)
+}
+
+// Find the key of a map which matches a key segment. String keys are matched
+// first. Otherwise the segment is interpreted as a YAML scalar so `404` finds
+// an integer key, `1.5` a float key and `true` a boolean key. When FoldKeys is
+// set string keys are matched with foldKey and ConflictError is returned if
+// more than one key matches. KeyError is returned if no key matches.
+func (s *Settings) mapKey(mapping map[interface{}]interface{}, name string) (interface{}, error) {
+	if s.FoldKeys {
+		var found interface{}
+		for key := range mapping {
+			if str, ok := key.(string); ok && foldKey(str, name) {
+				if found != nil {
+					return nil, ConflictError
+				}
+				found = str
+			}
+		}
+		if found != nil {
+			return found, nil
+		}
+	} else if _, ok := mapping[name]; ok {
+		return name, nil
+	}
+	if key, ok := scalarKey(name); ok {
+		if _, ok := mapping[key]; ok {
+			return key, nil
+		}
+	}
+	return nil, KeyError
+}
+
+// Get the value of the map key which matches a key segment. See mapKey.
+func (s *Settings) mapValue(mapping map[interface{}]interface{}, name string) (interface{}, error) {
+	if !s.FoldKeys {
+		if value, ok := mapping[name]; ok {
+			return value, nil
+		}
+	}
+	key, err := s.mapKey(mapping, name)
+	if err != nil {
+		return nil, err
+	}
+	return mapping[key], nil
+}
+
+// YAML 1.1 words which yaml.v2 reads as booleans or null.
+var scalarWords = []string{"y", "yes", "n", "no", "true", "false", "on", "off", "null"}
+
+// Return true if a key segment could be a non-string YAML scalar: a number,
+// `~` or one of the boolean or null words.
+func maybeScalar(name string) bool {
+	if name == "" {
+		return false
+	}
+	switch c := name[0]; {
+	case c >= '0' && c <= '9', c == '+', c == '-', c == '.', c == '~':
+		return true
+	}
+	for _, word := range scalarWords {
+		if strings.EqualFold(name, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// Return the non-string value a key segment represents when read as a YAML
+// scalar, so `404` is an integer and `y` a boolean. The second result is
+// false if the segment is a string.
+func scalarKey(name string) (interface{}, bool) {
+	if !maybeScalar(name) || strings.ContainsAny(name, " \t\n#") {
+		return nil, false
+	}
+	var value interface{}
+	if err := yaml.Unmarshal([]byte(name), &value); err == nil {
+		switch value.(type) {
+		case int, int64, uint64, float64, bool:
+			return value, true
+		case nil:
+			if name == "~" || strings.EqualFold(name, "null") {
+				return nil, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// Resolve an array index. Negative indices count back from the end of the
+// array, so -1 is the last element. Return IndexError if the index is not an
+// integer and RangeError if it is out of range.
+func arrayIndex(index string, length int) (int, error) {
+	n, err := strconv.Atoi(index)
+	if err != nil {
+		return 0, IndexError
+	}
+	if n < 0 {
+		n += length
+	}
+	if n < 0 || n >= length {
+		return 0, RangeError
+	}
+	return n, nil
+}
+
+// Resolve an array slice such as `1:3`, `:2` or `-2:`. Either bound may be
+// omitted and negative bounds count back from the end of the array. The end is
+// exclusive. `ok` is false if the index is not a slice.
+func arraySlice(index string, length int) (start, end int, ok bool, err error) {
+	colon := strings.IndexByte(index, ':')
+	if colon == -1 {
+		return 0, 0, false, nil
+	}
+	bounds := []int{0, length}
+	for n, bound := range []string{index[:colon], index[colon+1:]} {
+		if bound == "" {
+			continue
+		}
+		value, err := strconv.Atoi(bound)
+		if err != nil {
+			return 0, 0, true, IndexError
+		}
+		if value < 0 {
+			value += length
+		}
+		bounds[n] = value
+	}
+	start, end = bounds[0], bounds[1]
+	if start < 0 || start > end || end > length {
+		return 0, 0, true, RangeError
+	}
+	return start, end, true, nil
+}
+
 // Has returns true if a value exists.
 func (s *Settings) Has(key string) bool {
 	_, err := s.Raw(key)
@@ -43,34 +220,64 @@ func (s *Settings) Has(key string) bool {
 func (s *Settings) Object(key string) (*Settings, error) {
 	if value, err := s.Raw(key); err == nil {
 		if mapping, ok := value.(map[interface{}]interface{}); ok {
-			return &Settings{Key: key, Values: mapping}, nil
+			return s.child(key, mapping), nil
 		} else {
-			return nil, TypeError
+			return nil, s.keyError(key, typeError("object", value))
 		}
 	} else {
 		return nil, err
 	}
 }
 
+// Get the elements of an array along with their keys. Elements selected by a
+// `[?...]` filter are returned with their concrete keys.
+func (s *Settings) elements(key string) ([]string, []interface{}, error) {
+	path, err := s.parseKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if projects(path) {
+		matches, err := s.project(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		keys := make([]string, len(matches))
+		items := make([]interface{}, len(matches))
+		for n, match := range matches {
+			keys[n], items[n] = match.Key, match.Value
+		}
+		return keys, items, nil
+	}
+
+	value, err := s.rawPath(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, nil, typeError("array", value)
+	}
+	keys := make([]string, len(items))
+	for n := range items {
+		keys[n] = s.joinKey(key, n)
+	}
+	return keys, items, nil
+}
+
 // Get an array of settings objects.
 func (s *Settings) ObjectArray(key string) ([]*Settings, error) {
-	if value, err := s.Raw(key); err == nil {
-		if items, ok := value.([]interface{}); ok {
-			array := make([]*Settings, len(items))
-			for n, item := range items {
-				if mapping, ok := item.(map[interface{}]interface{}); ok {
-					settingsKey := fmt.Sprintf("%s.%d", key, n)
-					array[n] = &Settings{Key: settingsKey, Values: mapping}
-				} else {
-					return nil, TypeError
-				}
+	if keys, items, err := s.elements(key); err == nil {
+		array := make([]*Settings, len(items))
+		for n, item := range items {
+			if mapping, ok := item.(map[interface{}]interface{}); ok {
+				array[n] = s.child(keys[n], mapping)
+			} else {
+				return nil, s.keyError(keys[n], typeError("object", item))
 			}
-			return array, nil
-		} else {
-			return nil, TypeError
 		}
+		return array, nil
 	} else {
-		return nil, err
+		return nil, s.keyError(key, err)
 	}
 }
 
@@ -79,17 +286,17 @@ func (s *Settings) ObjectMap(key string) (map[string]*Settings, error) {
 	if raw, err := s.Raw(key); err == nil {
 		rawMap, ok := raw.(map[interface{}]interface{})
 		if !ok {
-			return nil, TypeError
+			return nil, s.keyError(key, typeError("object", raw))
 		}
 
 		objectMap := make(map[string]*Settings)
 		for rawMapKey, rawMapValue := range rawMap {
+			keyStr := fmt.Sprintf("%v", rawMapKey)
+			settingsKey := s.joinKey(key, keyStr)
 			if settingsValues, ok := rawMapValue.(map[interface{}]interface{}); ok {
-				keyStr := fmt.Sprintf("%v", rawMapKey)
-				settingsKey := key + "." + keyStr
-				objectMap[keyStr] = &Settings{Key: settingsKey, Values: settingsValues}
+				objectMap[keyStr] = s.child(settingsKey, settingsValues)
 			} else {
-				return nil, TypeError
+				return nil, s.keyError(settingsKey, typeError("object", rawMapValue))
 			}
 		}
 		return objectMap, nil
@@ -98,14 +305,108 @@ func (s *Settings) ObjectMap(key string) (map[string]*Settings, error) {
 	}
 }
 
+// Convert a value to a string. Scalars are formatted when coercion is enabled.
+func (s *Settings) getStringValue(value interface{}) (string, error) {
+	switch value.(type) {
+	case string:
+		return value.(string), nil
+	}
+	if s.Coerce {
+		switch value.(type) {
+		case int:
+			return strconv.Itoa(value.(int)), nil
+		case int64:
+			return strconv.FormatInt(value.(int64), 10), nil
+		case uint64:
+			return strconv.FormatUint(value.(uint64), 10), nil
+		case float64:
+			return strconv.FormatFloat(value.(float64), 'g', -1, 64), nil
+		case bool:
+			return strconv.FormatBool(value.(bool)), nil
+		}
+	}
+	return "", typeError("string", value)
+}
+
+// Convert a float to an int if it has no fractional part and is in range.
+func getIntFromFloat(value float64) (int, error) {
+	if value != math.Trunc(value) || value < math.MinInt || value >= -math.MinInt {
+		return 0, typeError("int", value)
+	}
+	return int(value), nil
+}
+
+// Convert a value to an int. Numeric strings and integral floats are converted
+// when coercion is enabled. Human friendly integer strings are parsed when
+// HumanInts is enabled.
+func (s *Settings) getIntValue(value interface{}) (int, error) {
+	switch value.(type) {
+	case int:
+		return value.(int), nil
+	}
+	if str, ok := value.(string); ok && s.HumanInts {
+		if n, err := ParseHumanInt(str); err != nil {
+			return 0, fmt.Errorf("%w: %w", TypeError, err)
+		} else if n < math.MinInt || n > math.MaxInt {
+			return 0, fmt.Errorf("%w: integer %q overflows int", TypeError, str)
+		} else {
+			return int(n), nil
+		}
+	}
+	if s.Coerce {
+		switch value.(type) {
+		case int64:
+			if n := value.(int64); n >= math.MinInt && n <= math.MaxInt {
+				return int(n), nil
+			}
+		case uint64:
+			if n := value.(uint64); n <= math.MaxInt {
+				return int(n), nil
+			}
+		case float64:
+			return getIntFromFloat(value.(float64))
+		case string:
+			str := strings.TrimSpace(value.(string))
+			if n, err := strconv.Atoi(str); err == nil {
+				return n, nil
+			} else if f, err := strconv.ParseFloat(str, 64); err == nil {
+				return getIntFromFloat(f)
+			}
+		}
+	}
+	return 0, typeError("int", value)
+}
+
+// Convert a value to a float. Numeric strings are converted when coercion is
+// enabled.
+func (s *Settings) getFloatValue(value interface{}) (float64, error) {
+	switch value.(type) {
+	case float64:
+		return value.(float64), nil
+	case int:
+		return float64(value.(int)), nil
+	}
+	if s.Coerce {
+		switch value.(type) {
+		case int64:
+			return float64(value.(int64)), nil
+		case uint64:
+			return float64(value.(uint64)), nil
+		case string:
+			str := strings.TrimSpace(value.(string))
+			if f, err := strconv.ParseFloat(str, 64); err == nil {
+				return f, nil
+			}
+		}
+	}
+	return 0, typeError("float", value)
+}
+
 // Get a string value.
 func (s *Settings) String(key string) (string, error) {
 	if value, err := s.Raw(key); err == nil {
-		if valueStr, ok := value.(string); ok {
-			return valueStr, nil
-		} else {
-			return "", TypeError
-		}
+		result, err := s.getStringValue(value)
+		return result, s.keyError(key, err)
 	} else {
 		return "", err
 	}
@@ -117,16 +418,15 @@ func (s *Settings) StringArray(key string) ([]string, error) {
 		if items, ok := value.([]interface{}); ok {
 			array := make([]string, len(items))
 			for n, item := range items {
-				switch item.(type) {
-				case string:
-					array[n] = item.(string)
-				default:
-					return nil, TypeError
+				if stringValue, err := s.getStringValue(item); err == nil {
+					array[n] = stringValue
+				} else {
+					return nil, s.keyError(s.joinKey(key, n), err)
 				}
 			}
 			return array, nil
 		} else {
-			return nil, TypeError
+			return nil, s.keyError(key, typeError("array", value))
 		}
 	} else {
 		return nil, err
@@ -138,16 +438,16 @@ func (s *Settings) StringMap(key string) (map[string]string, error) {
 	if raw, err := s.Raw(key); err == nil {
 		rawMap, ok := raw.(map[interface{}]interface{})
 		if !ok {
-			return nil, TypeError
+			return nil, s.keyError(key, typeError("object", raw))
 		}
 
 		stringMap := make(map[string]string)
 		for rawMapKey, rawMapValue := range rawMap {
-			if stringValue, ok := rawMapValue.(string); ok {
-				keyStr := fmt.Sprintf("%v", rawMapKey)
+			keyStr := fmt.Sprintf("%v", rawMapKey)
+			if stringValue, err := s.getStringValue(rawMapValue); err == nil {
 				stringMap[keyStr] = stringValue
 			} else {
-				return nil, TypeError
+				return nil, s.keyError(s.joinKey(key, keyStr), err)
 			}
 		}
 		return stringMap, nil
@@ -159,11 +459,8 @@ func (s *Settings) StringMap(key string) (map[string]string, error) {
 // Get an integer value.
 func (s *Settings) Int(key string) (int, error) {
 	if value, err := s.Raw(key); err == nil {
-		if valueInt, ok := value.(int); ok {
-			return valueInt, nil
-		} else {
-			return 0, TypeError
-		}
+		result, err := s.getIntValue(value)
+		return result, s.keyError(key, err)
 	} else {
 		return 0, err
 	}
@@ -175,16 +472,15 @@ func (s *Settings) IntArray(key string) ([]int, error) {
 		if items, ok := value.([]interface{}); ok {
 			array := make([]int, len(items))
 			for n, item := range items {
-				switch item.(type) {
-				case int:
-					array[n] = item.(int)
-				default:
-					return nil, TypeError
+				if intValue, err := s.getIntValue(item); err == nil {
+					array[n] = intValue
+				} else {
+					return nil, s.keyError(s.joinKey(key, n), err)
 				}
 			}
 			return array, nil
 		} else {
-			return nil, TypeError
+			return nil, s.keyError(key, typeError("array", value))
 		}
 	} else {
 		return nil, err
@@ -196,16 +492,16 @@ func (s *Settings) IntMap(key string) (map[string]int, error) {
 	if raw, err := s.Raw(key); err == nil {
 		rawMap, ok := raw.(map[interface{}]interface{})
 		if !ok {
-			return nil, TypeError
+			return nil, s.keyError(key, typeError("object", raw))
 		}
 
 		intMap := make(map[string]int)
 		for rawMapKey, rawMapValue := range rawMap {
-			if intValue, ok := rawMapValue.(int); ok {
-				keyStr := fmt.Sprintf("%v", rawMapKey)
+			keyStr := fmt.Sprintf("%v", rawMapKey)
+			if intValue, err := s.getIntValue(rawMapValue); err == nil {
 				intMap[keyStr] = intValue
 			} else {
-				return nil, TypeError
+				return nil, s.keyError(s.joinKey(key, keyStr), err)
 			}
 		}
 		return intMap, nil
@@ -217,14 +513,8 @@ func (s *Settings) IntMap(key string) (map[string]int, error) {
 // Get a float value.
 func (s *Settings) Float(key string) (float64, error) {
 	if value, err := s.Raw(key); err == nil {
-		switch value.(type) {
-		case float64:
-			return value.(float64), nil
-		case int:
-			return float64(value.(int)), nil
-		default:
-			return 0, TypeError
-		}
+		result, err := s.getFloatValue(value)
+		return result, s.keyError(key, err)
 	} else {
 		return 0, err
 	}
@@ -236,18 +526,15 @@ func (s *Settings) FloatArray(key string) ([]float64, error) {
 		if items, ok := value.([]interface{}); ok {
 			array := make([]float64, len(items))
 			for n, item := range items {
-				switch item.(type) {
-				case float64:
-					array[n] = item.(float64)
-				case int:
-					array[n] = float64(item.(int))
-				default:
-					return nil, TypeError
+				if floatValue, err := s.getFloatValue(item); err == nil {
+					array[n] = floatValue
+				} else {
+					return nil, s.keyError(s.joinKey(key, n), err)
 				}
 			}
 			return array, nil
 		} else {
-			return nil, TypeError
+			return nil, s.keyError(key, typeError("array", value))
 		}
 	} else {
 		return nil, err
@@ -259,19 +546,16 @@ func (s *Settings) FloatMap(key string) (map[string]float64, error) {
 	if raw, err := s.Raw(key); err == nil {
 		rawMap, ok := raw.(map[interface{}]interface{})
 		if !ok {
-			return nil, TypeError
+			return nil, s.keyError(key, typeError("object", raw))
 		}
 
 		floatMap := make(map[string]float64)
 		for rawMapKey, rawMapValue := range rawMap {
-			if intValue, ok := rawMapValue.(int); ok {
-				keyStr := fmt.Sprintf("%v", rawMapKey)
-				floatMap[keyStr] = float64(intValue)
-			} else if floatValue, ok := rawMapValue.(float64); ok {
-				keyStr := fmt.Sprintf("%v", rawMapKey)
+			keyStr := fmt.Sprintf("%v", rawMapKey)
+			if floatValue, err := s.getFloatValue(rawMapValue); err == nil {
 				floatMap[keyStr] = floatValue
 			} else {
-				return nil, TypeError
+				return nil, s.keyError(s.joinKey(key, keyStr), err)
 			}
 		}
 		return floatMap, nil
@@ -293,17 +577,18 @@ func getBoolValue(value interface{}) (bool, error) {
 		if valueBool, err := strconv.ParseBool(value.(string)); err == nil {
 			return valueBool, nil
 		} else {
-			return false, TypeError
+			return false, typeError("bool", value)
 		}
 	default:
-		return false, TypeError
+		return false, typeError("bool", value)
 	}
 }
 
 // Get a boolean value.
 func (s *Settings) Bool(key string) (bool, error) {
 	if value, err := s.Raw(key); err == nil {
-		return getBoolValue(value)
+		result, err := getBoolValue(value)
+		return result, s.keyError(key, err)
 	} else {
 		return false, err
 	}
@@ -318,12 +603,12 @@ func (s *Settings) BoolArray(key string) ([]bool, error) {
 				if boolValue, err := getBoolValue(item); err == nil {
 					array[n] = boolValue
 				} else {
-					return nil, err
+					return nil, s.keyError(s.joinKey(key, n), err)
 				}
 			}
 			return array, nil
 		} else {
-			return nil, TypeError
+			return nil, s.keyError(key, typeError("array", value))
 		}
 	} else {
 		return nil, err
@@ -335,7 +620,7 @@ func (s *Settings) BoolMap(key string) (map[string]bool, error) {
 	if raw, err := s.Raw(key); err == nil {
 		rawMap, ok := raw.(map[interface{}]interface{})
 		if !ok {
-			return nil, TypeError
+			return nil, s.keyError(key, typeError("object", raw))
 		}
 
 		boolMap := make(map[string]bool)
@@ -344,7 +629,7 @@ func (s *Settings) BoolMap(key string) (map[string]bool, error) {
 			if boolValue, err := getBoolValue(rawMapValue); err == nil {
 				boolMap[keyStr] = boolValue
 			} else {
-				return nil, err
+				return nil, s.keyError(s.joinKey(key, keyStr), err)
 			}
 		}
 		return boolMap, nil
@@ -353,12 +638,31 @@ func (s *Settings) BoolMap(key string) (map[string]bool, error) {
 	}
 }
 
-// Get a duration value.
+// Convert a value to a duration. Numbers are multiplied by DurationUnit.
+func (s *Settings) getDurationValue(value interface{}) (time.Duration, error) {
+	unit := s.DurationUnit
+	if unit == 0 {
+		unit = time.Second
+	}
+	switch value.(type) {
+	case string:
+		return ParseDurationUnit(value.(string), unit)
+	case int:
+		return ParseDurationUnit(strconv.Itoa(value.(int)), unit)
+	case float64:
+		return ParseDurationUnit(strconv.FormatFloat(value.(float64), 'f', -1, 64), unit)
+	default:
+		return 0, typeError("duration", value)
+	}
+}
+
+// Get a duration value. See ParseDurationUnit for the accepted formats.
 func (s *Settings) Duration(key string) (time.Duration, error) {
-	if value, err := s.String(key); err == nil {
-		return time.ParseDuration(value)
+	if value, err := s.Raw(key); err == nil {
+		result, err := s.getDurationValue(value)
+		return result, s.keyError(key, err)
 	} else {
-		return 0 * time.Nanosecond, err
+		return 0, err
 	}
 }
 
@@ -368,16 +672,15 @@ func (s *Settings) DurationArray(key string) ([]time.Duration, error) {
 		if items, ok := value.([]interface{}); ok {
 			array := make([]time.Duration, len(items))
 			for n, item := range items {
-				itemStr := fmt.Sprint(item)
-				if durationValue, err := time.ParseDuration(itemStr); err == nil {
+				if durationValue, err := s.getDurationValue(item); err == nil {
 					array[n] = durationValue
 				} else {
-					return nil, err
+					return nil, s.keyError(s.joinKey(key, n), err)
 				}
 			}
 			return array, nil
 		} else {
-			return nil, TypeError
+			return nil, s.keyError(key, typeError("array", value))
 		}
 	} else {
 		return nil, err
@@ -389,17 +692,16 @@ func (s *Settings) DurationMap(key string) (map[string]time.Duration, error) {
 	if raw, err := s.Raw(key); err == nil {
 		rawMap, ok := raw.(map[interface{}]interface{})
 		if !ok {
-			return nil, TypeError
+			return nil, s.keyError(key, typeError("object", raw))
 		}
 
 		durationMap := make(map[string]time.Duration)
 		for rawMapKey, rawMapValue := range rawMap {
 			keyStr := fmt.Sprintf("%v", rawMapKey)
-			valueStr := fmt.Sprint(rawMapValue)
-			if durationValue, err := time.ParseDuration(valueStr); err == nil {
+			if durationValue, err := s.getDurationValue(rawMapValue); err == nil {
 				durationMap[keyStr] = durationValue
 			} else {
-				return nil, err
+				return nil, s.keyError(s.joinKey(key, keyStr), err)
 			}
 		}
 		return durationMap, nil
@@ -408,10 +710,27 @@ func (s *Settings) DurationMap(key string) (map[string]time.Duration, error) {
 	}
 }
 
+// Convert a value to a size in bytes. Numbers are taken to be bytes.
+func (s *Settings) getSizeValue(value interface{}) (int64, error) {
+	switch value.(type) {
+	case string:
+		return s.SizeMode.Parse(value.(string))
+	case int:
+		return int64(value.(int)), nil
+	case int64:
+		return value.(int64), nil
+	case float64:
+		return s.SizeMode.Parse(strconv.FormatFloat(value.(float64), 'f', -1, 64))
+	default:
+		return 0, typeError("size", value)
+	}
+}
+
 // Get a settings value as a size (in bytes).
 func (s *Settings) Size(key string) (int64, error) {
-	if value, err := s.String(key); err == nil {
-		return ParseSize(value)
+	if value, err := s.Raw(key); err == nil {
+		result, err := s.getSizeValue(value)
+		return result, s.keyError(key, err)
 	} else {
 		return 0, err
 	}
@@ -423,16 +742,15 @@ func (s *Settings) SizeArray(key string) ([]int64, error) {
 		if items, ok := value.([]interface{}); ok {
 			array := make([]int64, len(items))
 			for n, item := range items {
-				itemStr := fmt.Sprint(item)
-				if sizeValue, err := ParseSize(itemStr); err == nil {
+				if sizeValue, err := s.getSizeValue(item); err == nil {
 					array[n] = sizeValue
 				} else {
-					return nil, err
+					return nil, s.keyError(s.joinKey(key, n), err)
 				}
 			}
 			return array, nil
 		} else {
-			return nil, TypeError
+			return nil, s.keyError(key, typeError("array", value))
 		}
 	} else {
 		return nil, err
@@ -444,17 +762,16 @@ func (s *Settings) SizeMap(key string) (map[string]int64, error) {
 	if raw, err := s.Raw(key); err == nil {
 		rawMap, ok := raw.(map[interface{}]interface{})
 		if !ok {
-			return nil, TypeError
+			return nil, s.keyError(key, typeError("object", raw))
 		}
 
 		sizeMap := make(map[string]int64)
 		for rawMapKey, rawMapValue := range rawMap {
 			keyStr := fmt.Sprintf("%v", rawMapKey)
-			valueStr := fmt.Sprint(rawMapValue)
-			if sizeValue, err := ParseSize(valueStr); err == nil {
+			if sizeValue, err := s.getSizeValue(rawMapValue); err == nil {
 				sizeMap[keyStr] = sizeValue
 			} else {
-				return nil, err
+				return nil, s.keyError(s.joinKey(key, keyStr), err)
 			}
 		}
 		return sizeMap, nil
@@ -462,3 +779,445 @@ func (s *Settings) SizeMap(key string) (map[string]int64, error) {
 		return nil, err
 	}
 }
+
+// Convert a value to a compiled regular expression. Compiled expressions are
+// cached on the settings object so that each pattern is only compiled once.
+func (s *Settings) getRegexpValue(key string, value interface{}) (*regexp.Regexp, error) {
+	pattern, ok := value.(string)
+	if !ok {
+		return nil, typeError("regexp", value)
+	}
+	if cached, ok := s.Root().regexps.Load(pattern); ok {
+		return cached.(*regexp.Regexp), nil
+	}
+	if re, err := regexp.Compile(pattern); err == nil {
+		s.Root().regexps.Store(pattern, re)
+		return re, nil
+	} else {
+		return nil, s.keyError(key, err)
+	}
+}
+
+// Get a regular expression value.
+func (s *Settings) Regexp(key string) (*regexp.Regexp, error) {
+	if value, err := s.Raw(key); err == nil {
+		result, err := s.getRegexpValue(key, value)
+		return result, s.keyError(key, err)
+	} else {
+		return nil, err
+	}
+}
+
+// Get an array of regular expression values.
+func (s *Settings) RegexpArray(key string) ([]*regexp.Regexp, error) {
+	if value, err := s.Raw(key); err == nil {
+		if items, ok := value.([]interface{}); ok {
+			array := make([]*regexp.Regexp, len(items))
+			for n, item := range items {
+				itemKey := s.joinKey(key, n)
+				if re, err := s.getRegexpValue(itemKey, item); err == nil {
+					array[n] = re
+				} else {
+					return nil, s.keyError(s.joinKey(key, n), err)
+				}
+			}
+			return array, nil
+		} else {
+			return nil, s.keyError(key, typeError("array", value))
+		}
+	} else {
+		return nil, err
+	}
+}
+
+// Get a map of regular expressions.
+func (s *Settings) RegexpMap(key string) (map[string]*regexp.Regexp, error) {
+	if raw, err := s.Raw(key); err == nil {
+		rawMap, ok := raw.(map[interface{}]interface{})
+		if !ok {
+			return nil, s.keyError(key, typeError("object", raw))
+		}
+
+		regexpMap := make(map[string]*regexp.Regexp)
+		for rawMapKey, rawMapValue := range rawMap {
+			keyStr := fmt.Sprintf("%v", rawMapKey)
+			if re, err := s.getRegexpValue(s.joinKey(key, keyStr), rawMapValue); err == nil {
+				regexpMap[keyStr] = re
+			} else {
+				return nil, s.keyError(s.joinKey(key, keyStr), err)
+			}
+		}
+		return regexpMap, nil
+	} else {
+		return nil, err
+	}
+}
+
+// Get a string value which must match one of the provided choices. The
+// canonical allowed value is returned. A *PathError wrapping an *EnumError is
+// returned if the value does not match.
+func (s *Settings) Choice(key string, choices Choices) (string, error) {
+	if value, err := s.String(key); err == nil {
+		if match, err := choices.Match(value); err == nil {
+			return match, nil
+		} else {
+			return "", s.keyError(key, err)
+		}
+	} else {
+		return "", err
+	}
+}
+
+// Get a string value which must be one of the `allowed` values.
+func (s *Settings) Enum(key string, allowed ...string) (string, error) {
+	return s.Choice(key, Choices{Allowed: allowed})
+}
+
+// Convert a value to an expanded path and verify it against the checks.
+func (s *Settings) getPathValue(key string, value interface{}, checks []PathCheck) (string, error) {
+	var check PathCheck
+	for _, c := range checks {
+		check |= c
+	}
+
+	str, err := s.getStringValue(value)
+	if err != nil {
+		return "", err
+	}
+	dir := ""
+	if s.File != "" {
+		dir = filepath.Dir(s.File)
+	}
+	path, err := ExpandPath(str, dir)
+	if err == nil {
+		err = checkPath(path, check)
+	}
+	if err != nil {
+		return "", s.keyError(key, err)
+	}
+	return path, nil
+}
+
+// Get a filesystem path. A leading `~` and environment variables are expanded
+// and relative paths are resolved against the directory of the file the
+// settings were loaded from. The path must satisfy all of the provided checks.
+func (s *Settings) Path(key string, checks ...PathCheck) (string, error) {
+	if value, err := s.Raw(key); err == nil {
+		result, err := s.getPathValue(key, value, checks)
+		return result, s.keyError(key, err)
+	} else {
+		return "", err
+	}
+}
+
+// Get an array of filesystem paths. Each path must satisfy all of the provided
+// checks.
+func (s *Settings) PathArray(key string, checks ...PathCheck) ([]string, error) {
+	if value, err := s.Raw(key); err == nil {
+		if items, ok := value.([]interface{}); ok {
+			array := make([]string, len(items))
+			for n, item := range items {
+				itemKey := s.joinKey(key, n)
+				if path, err := s.getPathValue(itemKey, item, checks); err == nil {
+					array[n] = path
+				} else {
+					return nil, s.keyError(itemKey, err)
+				}
+			}
+			return array, nil
+		} else {
+			return nil, s.keyError(key, typeError("array", value))
+		}
+	} else {
+		return nil, err
+	}
+}
+
+// Get a file mode from an octal integer such as `0640` or an octal string
+// such as `"0o2755"`. Integers written without a leading `0` in the source are
+// rejected with a TypeError since YAML reads `640` as decimal.
+func (s *Settings) FileMode(key string) (os.FileMode, error) {
+	if value, err := s.Raw(key); err == nil {
+		switch value.(type) {
+		case int:
+			if !s.isOctal(key, value.(int)) {
+				return 0, s.keyError(key, typeError("octal file mode", value))
+			}
+			mode, err := getFileMode(uint64(value.(int)))
+			return mode, s.keyError(key, err)
+		case string:
+			mode, err := ParseFileMode(value.(string))
+			return mode, s.keyError(key, err)
+		}
+		return 0, s.keyError(key, typeError("file mode", value))
+	} else {
+		return 0, err
+	}
+}
+
+// Return true if the integer at `key` is written in octal in the source.
+// yaml.v2 reads a leading `0` or `0o` as octal but the prefix is lost once the
+// value is decoded.
+func (s *Settings) isOctal(key string, value int) bool {
+	node := s.sourceNode(key)
+	if node == nil || !strings.HasPrefix(node.text, "0") {
+		return false
+	}
+	n, err := strconv.ParseInt(strings.TrimPrefix(strings.ToLower(node.text), "0o"), 8, 64)
+	return err == nil && n == int64(value)
+}
+
+// Get a user ID. The value may be a user name or a numeric ID.
+func (s *Settings) UserID(key string) (int, error) {
+	if value, err := s.Raw(key); err == nil {
+		switch value.(type) {
+		case int:
+			return value.(int), nil
+		case string:
+			uid, err := ParseUserID(value.(string))
+			return uid, s.keyError(key, err)
+		}
+		return 0, s.keyError(key, typeError("user ID", value))
+	} else {
+		return 0, err
+	}
+}
+
+// Get a group ID. The value may be a group name or a numeric ID.
+func (s *Settings) GroupID(key string) (int, error) {
+	if value, err := s.Raw(key); err == nil {
+		switch value.(type) {
+		case int:
+			return value.(int), nil
+		case string:
+			gid, err := ParseGroupID(value.(string))
+			return gid, s.keyError(key, err)
+		}
+		return 0, s.keyError(key, typeError("group ID", value))
+	} else {
+		return 0, err
+	}
+}
+
+// Get a range of integers. See ParseRange for the accepted string formats.
+// Objects with `min` and `max` keys are also accepted.
+func (s *Settings) Range(key string) (Range, error) {
+	if value, err := s.Raw(key); err == nil {
+		result, err := s.getRangeValue(key, value)
+		return result, s.keyError(key, err)
+	} else {
+		return Range{}, err
+	}
+}
+
+// Get a range of ports. Both bounds must be valid port numbers.
+func (s *Settings) PortRange(key string) (Range, error) {
+	if r, err := s.Range(key); err == nil {
+		if r.Min < 0 || r.Max > 65535 {
+			return Range{}, s.keyError(key, fmt.Errorf("port range %s out of bounds", r))
+		}
+		return r, nil
+	} else {
+		return Range{}, err
+	}
+}
+
+// Convert a value to a ratio in the range [0, 1].
+func getRatioValue(value interface{}) (float64, error) {
+	switch value.(type) {
+	case int:
+		return checkRatio(float64(value.(int)))
+	case float64:
+		return checkRatio(value.(float64))
+	case string:
+		return ParseRatio(value.(string))
+	default:
+		return 0, typeError("ratio", value)
+	}
+}
+
+// Get a ratio in the range [0, 1]. Values may be written as decimals (`0.75`),
+// percentages (`"75%"`) or fractions (`"3/4"`).
+func (s *Settings) Ratio(key string) (float64, error) {
+	if value, err := s.Raw(key); err == nil {
+		result, err := getRatioValue(value)
+		return result, s.keyError(key, err)
+	} else {
+		return 0, err
+	}
+}
+
+// Get an array of ratios.
+func (s *Settings) RatioArray(key string) ([]float64, error) {
+	if value, err := s.Raw(key); err == nil {
+		if items, ok := value.([]interface{}); ok {
+			array := make([]float64, len(items))
+			for n, item := range items {
+				if ratioValue, err := getRatioValue(item); err == nil {
+					array[n] = ratioValue
+				} else {
+					return nil, s.keyError(s.joinKey(key, n), err)
+				}
+			}
+			return array, nil
+		} else {
+			return nil, s.keyError(key, typeError("array", value))
+		}
+	} else {
+		return nil, err
+	}
+}
+
+// Get a map of ratios.
+func (s *Settings) RatioMap(key string) (map[string]float64, error) {
+	if raw, err := s.Raw(key); err == nil {
+		rawMap, ok := raw.(map[interface{}]interface{})
+		if !ok {
+			return nil, s.keyError(key, typeError("object", raw))
+		}
+
+		ratioMap := make(map[string]float64)
+		for rawMapKey, rawMapValue := range rawMap {
+			keyStr := fmt.Sprintf("%v", rawMapKey)
+			if ratioValue, err := getRatioValue(rawMapValue); err == nil {
+				ratioMap[keyStr] = ratioValue
+			} else {
+				return nil, s.keyError(s.joinKey(key, keyStr), err)
+			}
+		}
+		return ratioMap, nil
+	} else {
+		return nil, err
+	}
+}
+
+// Get binary data. YAML `!!binary` values are returned as is and strings are
+// decoded with ParseBytes.
+func (s *Settings) Bytes(key string) ([]byte, error) {
+	if value, err := s.Raw(key); err == nil {
+		switch value.(type) {
+		case []byte:
+			return value.([]byte), nil
+		case string:
+			if data, ok := s.binaryValue(key, value.(string)); ok {
+				return data, nil
+			}
+			if data, err := ParseBytes(value.(string)); err == nil {
+				return data, nil
+			} else {
+				return nil, s.keyError(key, err)
+			}
+		default:
+			return nil, s.keyError(key, typeError("bytes", value))
+		}
+	} else {
+		return nil, err
+	}
+}
+
+// Get PEM encoded certificates. The value may be inline PEM data, a path to a
+// PEM file, or an array of either.
+func (s *Settings) Certificates(key string) ([]*x509.Certificate, error) {
+	value, err := s.Raw(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []interface{}
+	if array, ok := value.([]interface{}); ok {
+		items = array
+	} else {
+		items = []interface{}{value}
+	}
+
+	var certs []*x509.Certificate
+	for n, item := range items {
+		itemKey := key
+		if len(items) > 1 {
+			itemKey = s.joinKey(key, n)
+		}
+		data, err := s.getPEMData(itemKey, item)
+		if err != nil {
+			return nil, s.keyError(itemKey, err)
+		}
+		if parsed, err := ParseCertificates(data); err == nil {
+			certs = append(certs, parsed...)
+		} else {
+			return nil, s.keyError(itemKey, err)
+		}
+	}
+	return certs, nil
+}
+
+// Get a PEM encoded private key. The value may be inline PEM data or a path to
+// a PEM file.
+func (s *Settings) PrivateKey(key string) (crypto.Signer, error) {
+	if value, err := s.Raw(key); err == nil {
+		data, err := s.getPEMData(key, value)
+		if err != nil {
+			return nil, s.keyError(key, err)
+		}
+		if signer, err := ParsePrivateKey(data); err == nil {
+			return signer, nil
+		} else {
+			return nil, s.keyError(key, err)
+		}
+	} else {
+		return nil, err
+	}
+}
+
+// Get a time of day in `hh:mm` or `hh:mm:ss` format.
+func (s *Settings) TimeOfDay(key string) (TimeOfDay, error) {
+	if value, err := s.String(key); err == nil {
+		if t, err := ParseTimeOfDay(value); err == nil {
+			return t, nil
+		} else {
+			return TimeOfDay{}, s.keyError(key, err)
+		}
+	} else {
+		return TimeOfDay{}, err
+	}
+}
+
+// Get a recurring window of time. The value is an object such as
+// `{days: [sat, sun], from: "01:00", to: "05:00", tz: UTC}`.
+func (s *Settings) Window(key string) (Window, error) {
+	if value, err := s.Raw(key); err == nil {
+		result, err := s.getWindowValue(key, value)
+		return result, s.keyError(key, err)
+	} else {
+		return Window{}, err
+	}
+}
+
+// Get a schedule of recurring windows. The value is an array of window
+// objects or a single window object.
+func (s *Settings) Schedule(key string) (Schedule, error) {
+	value, err := s.Raw(key)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := value.(map[interface{}]interface{}); ok {
+		if w, err := s.getWindowValue(key, value); err == nil {
+			return Schedule{w}, nil
+		} else {
+			return nil, s.keyError(key, err)
+		}
+	}
+
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, s.keyError(key, typeError("array", value))
+	}
+	schedule := make(Schedule, len(items))
+	for n, item := range items {
+		itemKey := s.joinKey(key, n)
+		if w, err := s.getWindowValue(itemKey, item); err == nil {
+			schedule[n] = w
+		} else {
+			return nil, s.keyError(itemKey, err)
+		}
+	}
+	return schedule, nil
+}
@@ -1,7 +1,12 @@
 package settings
 
 import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -9,7 +14,7 @@ import (
 func TestRaw(t *testing.T) {
 	// get a value from a new object
 	settings := &Settings{}
-	if _, err := settings.Raw("nope"); err != KeyError {
+	if _, err := settings.Raw("nope"); !errors.Is(err, KeyError) {
 		t.Errorf("empty settings error is invalid: %s", err)
 	}
 
@@ -63,20 +68,20 @@ func TestRaw(t *testing.T) {
 
 	// retrieve missing value
 	key = "sir-not-appearing-in-this-film"
-	if _, err := settings.Raw(key); err != KeyError {
+	if _, err := settings.Raw(key); !errors.Is(err, KeyError) {
 		t.Errorf("%s did not cause a KeyError", key)
 	}
 
 	// retrieve missing map value
 	key = "mapping.c"
-	if _, err := settings.Raw(key); err != KeyError {
+	if _, err := settings.Raw(key); !errors.Is(err, KeyError) {
 		t.Errorf("%s did not cause a KeyError", key)
 	}
 
 	// retrieve missing array value
 	key = "string-array.3"
-	if _, err := settings.Raw(key); err != KeyError {
-		t.Errorf("%s did not cause a KeyError", key)
+	if _, err := settings.Raw(key); !errors.Is(err, RangeError) {
+		t.Errorf("%s did not cause a RangeError", key)
 	}
 
 	// retrieve a bool
@@ -120,6 +125,194 @@ func TestRaw(t *testing.T) {
 	}
 }
 
+func TestRawKeyPath(t *testing.T) {
+	settings, _ := Parse([]byte(`hosts:
+  api.example.com:
+    port: 8443`))
+
+	keys := []string{
+		`hosts."api.example.com".port`,
+		`hosts.api\.example\.com.port`,
+		NewKeyPath("hosts", "api.example.com", "port").String(),
+	}
+	for _, key := range keys {
+		if value, err := settings.Int(key); err == nil {
+			if value != 8443 {
+				t.Errorf("%v != %v", 8443, value)
+			}
+		} else {
+			t.Errorf("key %s: %s", key, err)
+		}
+	}
+
+	if _, err := settings.Raw(`hosts."api`); !errors.Is(err, SyntaxError) {
+		t.Errorf("key %s is valid", `hosts."api`)
+	}
+
+	// json pointers
+	settings.Pointers = true
+	if value, err := settings.Int("/hosts/api.example.com/port"); err != nil || value != 8443 {
+		t.Errorf("%v != %v (%v)", 8443, value, err)
+	}
+	if value, err := settings.Raw(""); err != nil || !reflect.DeepEqual(value, settings.Values) {
+		t.Errorf("%v != %v (%v)", settings.Values, value, err)
+	}
+	if objects, err := settings.ObjectMap("/hosts"); err == nil {
+		if key := objects["api.example.com"].Key; key != "/hosts/api.example.com" {
+			t.Errorf("%s != %s", "/hosts/api.example.com", key)
+		}
+		if value, err := objects["api.example.com"].Int("/port"); err != nil || value != 8443 {
+			t.Errorf("%v != %v (%v)", 8443, value, err)
+		}
+	} else {
+		t.Error(err)
+	}
+}
+
+func TestRawIndex(t *testing.T) {
+	settings, _ := Parse([]byte(`items: [a, b, c, d]`))
+
+	type test struct {
+		key  string
+		want interface{}
+	}
+
+	tests := []test{
+		{"items.-1", "d"},
+		{"items.-4", "a"},
+		{"items.1:3", []interface{}{"b", "c"}},
+		{"items.:2", []interface{}{"a", "b"}},
+		{"items.-2:", []interface{}{"c", "d"}},
+		{"items.:", []interface{}{"a", "b", "c", "d"}},
+		{"items.2:2", []interface{}{}},
+		{"items.1:3.-1", "c"},
+	}
+
+	for _, test := range tests {
+		if value, err := settings.Raw(test.key); err != nil {
+			t.Errorf("%s: %s", test.key, err)
+		} else if !reflect.DeepEqual(value, test.want) {
+			t.Errorf("%s: %v != %v", test.key, value, test.want)
+		}
+	}
+
+	invalid := map[string]error{
+		"items.4":   RangeError,
+		"items.-5":  RangeError,
+		"items.3:2": RangeError,
+		"items.0:5": RangeError,
+		"items.a:b": IndexError,
+		"items.x":   IndexError,
+	}
+	for key, want := range invalid {
+		if _, err := settings.Raw(key); !errors.Is(err, want) {
+			t.Errorf("%s: %v is not a %v", key, err, want)
+		}
+	}
+
+	if value, err := settings.StringArray("items.1:"); err != nil || !reflect.DeepEqual(value, []string{"b", "c", "d"}) {
+		t.Errorf("invalid slice: %v (%v)", value, err)
+	}
+}
+
+func TestRawScalarKeys(t *testing.T) {
+	settings, _ := Parse([]byte(`errors:
+  404: not-found.html
+  500: error.html
+  "503": unavailable.html
+flags:
+  true: on
+  false: off
+ratios:
+  0.5: half
+  1: one
+nulls:
+  ~: nothing
+point: {x: 1, y: 2}`))
+
+	strings := map[string]string{
+		"errors.404":  "not-found.html",
+		"errors.500":  "error.html",
+		"errors.503":  "unavailable.html",
+		"flags.true":  "true",
+		"flags.yes":   "true",
+		"flags.False": "false",
+		"ratios.1":    "one",
+		"nulls.null":  "nothing",
+		"nulls.~":     "nothing",
+		"point.x":     "1",
+		"point.y":     "2",
+	}
+	settings.Coerce = true
+	for key, want := range strings {
+		if value, err := settings.String(key); err != nil {
+			t.Errorf("%s: %s", key, err)
+		} else if value != want {
+			t.Errorf("%s: %s != %s", key, value, want)
+		}
+	}
+
+	if value, err := settings.String(`ratios."0.5"`); err != nil || value != "half" {
+		t.Errorf("%v != %v (%v)", "half", value, err)
+	}
+	if _, err := settings.String("errors.403"); !errors.Is(err, KeyError) {
+		t.Errorf("errors.403 did not cause a KeyError")
+	}
+
+	settings.Pointers = true
+	if value, err := settings.String("/errors/404"); err != nil || value != "not-found.html" {
+		t.Errorf("%v != %v (%v)", "not-found.html", value, err)
+	}
+
+	// segments which cannot be scalars skip the YAML decoder
+	allocs := testing.AllocsPerRun(100, func() {
+		scalarKey("missing")
+	})
+	if allocs != 0 {
+		t.Errorf("string segment allocated %v times", allocs)
+	}
+}
+
+func TestFoldKeys(t *testing.T) {
+	settings, _ := Parse([]byte(`pool:
+  max_conns: 10
+  IdleTimeout: 5s
+  server-name: db
+dup:
+  maxConns: 1
+  max-conns: 2`))
+
+	if _, err := settings.Int("pool.maxConns"); !errors.Is(err, KeyError) {
+		t.Errorf("key matched without FoldKeys: %v", err)
+	}
+
+	settings.FoldKeys = true
+	ints := []string{"pool.max_conns", "pool.max-conns", "pool.maxConns", "pool.MAX_CONNS", "Pool.MaxConns"}
+	for _, key := range ints {
+		if value, err := settings.Int(key); err != nil || value != 10 {
+			t.Errorf("%s: %v != %v (%v)", key, value, 10, err)
+		}
+	}
+	if value, err := settings.Duration("pool.idle_timeout"); err != nil || value != 5*time.Second {
+		t.Errorf("%v != %v (%v)", 5*time.Second, value, err)
+	}
+
+	object, err := settings.Object("POOL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, err := object.String("serverName"); err != nil || value != "db" {
+		t.Errorf("%v != %v (%v)", "db", value, err)
+	}
+
+	if _, err := settings.Int("dup.max_conns"); !errors.Is(err, ConflictError) {
+		t.Errorf("conflicting keys did not cause a ConflictError: %v", err)
+	}
+	if _, err := settings.Int("pool.min_conns"); !errors.Is(err, KeyError) {
+		t.Errorf("missing key did not cause a KeyError: %v", err)
+	}
+}
+
 func TestHas(t *testing.T) {
 	type testInput struct {
 		key string
@@ -159,7 +352,7 @@ func TestObject(t *testing.T) {
 		values["string"] = "value"
 		values["duration"] = "5m"
 		values["size"] = "15t"
-		want := &Settings{Key: key, Values: values}
+		want := &Settings{Key: key, Values: values, parent: settings, root: settings}
 		if !reflect.DeepEqual(want, item) {
 			t.Errorf("%v != %v", want, item)
 		}
@@ -169,13 +362,13 @@ func TestObject(t *testing.T) {
 
 	// test missing value
 	key = "missing"
-	if _, err := settings.Object(key); err != KeyError {
+	if _, err := settings.Object(key); !errors.Is(err, KeyError) {
 		t.Errorf("key %s found", key)
 	}
 
 	// test invalid value
 	key = "string-array"
-	if _, err := settings.Object(key); err != TypeError {
+	if _, err := settings.Object(key); !errors.Is(err, TypeError) {
 		t.Errorf("key %s is valid", key)
 	}
 }
@@ -194,8 +387,8 @@ func TestObjectArray(t *testing.T) {
 		want2 := make(map[interface{}]interface{}, 2)
 		want2["name"] = "two"
 		want2["value"] = "Me too!"
-		want[0] = &Settings{Key: "settings-array.0", Values: want1}
-		want[1] = &Settings{Key: "settings-array.1", Values: want2}
+		want[0] = &Settings{Key: "settings-array.0", Values: want1, parent: settings, root: settings}
+		want[1] = &Settings{Key: "settings-array.1", Values: want2, parent: settings, root: settings}
 
 		if !reflect.DeepEqual(want, items) {
 			t.Errorf("%v != %v", want, items)
@@ -211,13 +404,13 @@ func TestObjectArray(t *testing.T) {
 
 	// check missing settings array
 	key = "missing"
-	if _, err := settings.ObjectArray(key); err != KeyError {
+	if _, err := settings.ObjectArray(key); !errors.Is(err, KeyError) {
 		t.Errorf("key %s found", key)
 	}
 
 	// check invalid type
 	key = "string-array"
-	if _, err := settings.ObjectArray(key); err != TypeError {
+	if _, err := settings.ObjectArray(key); !errors.Is(err, TypeError) {
 		t.Errorf("key %s is valid", key)
 	}
 }
@@ -228,10 +421,10 @@ func TestObjectMap(t *testing.T) {
 	want := make(map[string]*Settings)
 	values := make(map[interface{}]interface{})
 	values["value"] = "I won!"
-	want["one"] = &Settings{Key: "settings-map.one", Values: values}
+	want["one"] = &Settings{Key: "settings-map.one", Values: values, parent: settings, root: settings}
 	values = make(map[interface{}]interface{})
 	values["value"] = "Me too!"
-	want["two"] = &Settings{Key: "settings-map.two", Values: values}
+	want["two"] = &Settings{Key: "settings-map.two", Values: values, parent: settings, root: settings}
 
 	if value, err := settings.ObjectMap("settings-map"); err == nil {
 		if !reflect.DeepEqual(want, value) {
@@ -269,7 +462,7 @@ func TestStringArray(t *testing.T) {
 
 	// mixed array
 	key := "mixed-array"
-	if _, err := settings.StringArray(key); err != TypeError {
+	if _, err := settings.StringArray(key); !errors.Is(err, TypeError) {
 		t.Errorf("key %s is valid", key)
 	}
 }
@@ -315,7 +508,7 @@ func TestIntArray(t *testing.T) {
 
 	// mixed array
 	key := "mixed-array"
-	if _, err := settings.IntArray(key); err != TypeError {
+	if _, err := settings.IntArray(key); !errors.Is(err, TypeError) {
 		t.Errorf("key %s is valid", key)
 	}
 }
@@ -334,6 +527,59 @@ func TestIntMap(t *testing.T) {
 	}
 }
 
+func TestHumanInts(t *testing.T) {
+	settings, _ := Parse([]byte(`queue: 1_000_000
+buffers: "10k"
+mask: "0xff"
+ambiguous: "010"
+counts:
+- "2M"
+- "0b11"
+- 7`))
+
+	// strict by default
+	if _, err := settings.Int("buffers"); !errors.Is(err, TypeError) {
+		t.Errorf("key %s is valid", "buffers")
+	}
+
+	settings.HumanInts = true
+	type test struct {
+		key  string
+		want int
+	}
+
+	tests := []test{
+		{"queue", 1000000},
+		{"buffers", 10000},
+		{"mask", 255},
+	}
+
+	for _, test := range tests {
+		if value, err := settings.Int(test.key); err == nil {
+			if test.want != value {
+				t.Errorf("%v != %v", test.want, value)
+			}
+		} else {
+			t.Error(err)
+		}
+	}
+
+	if _, err := settings.Int("ambiguous"); !errors.Is(err, TypeError) {
+		t.Errorf("key %s is valid", "ambiguous")
+	} else if !strings.Contains(err.Error(), `"010"`) {
+		t.Errorf("error does not describe the value: %v", err)
+	}
+
+	want := []int{2000000, 3, 7}
+	if value, err := settings.IntArray("counts"); err == nil {
+		if !reflect.DeepEqual(want, value) {
+			t.Errorf("%v != %v", want, value)
+		}
+	} else {
+		t.Error(err)
+	}
+}
+
 func TestFloat(t *testing.T) {
 	settings := getSettings()
 	want := 2.3
@@ -361,7 +607,7 @@ func TestFloatArray(t *testing.T) {
 
 	// mixed array
 	key := "mixed-array"
-	if _, err := settings.FloatArray(key); err != TypeError {
+	if _, err := settings.FloatArray(key); !errors.Is(err, TypeError) {
 		t.Errorf("key %s is valid", key)
 	}
 }
@@ -407,7 +653,7 @@ func TestBoolArray(t *testing.T) {
 
 	// mixed array
 	key := "mixed-array"
-	if _, err := settings.BoolArray(key); err != TypeError {
+	if _, err := settings.BoolArray(key); !errors.Is(err, TypeError) {
 		t.Errorf("key %s is valid", key)
 	}
 }
@@ -438,6 +684,34 @@ func TestDuration(t *testing.T) {
 	}
 }
 
+func TestDurationUnit(t *testing.T) {
+	settings, _ := Parse([]byte(`ttl: 30
+retention: 7d
+mixed:
+- 1
+- 2.5
+- P1D`))
+
+	// bare numbers are seconds by default
+	if value, err := settings.Duration("ttl"); err != nil || value != 30*time.Second {
+		t.Errorf("%v != %v (%v)", 30*time.Second, value, err)
+	}
+	if value, err := settings.Duration("retention"); err != nil || value != 7*24*time.Hour {
+		t.Errorf("%v != %v (%v)", 7*24*time.Hour, value, err)
+	}
+
+	// configured unit
+	settings.DurationUnit = time.Minute
+	want := []time.Duration{time.Minute, 150 * time.Second, 24 * time.Hour}
+	if value, err := settings.DurationArray("mixed"); err == nil {
+		if !reflect.DeepEqual(want, value) {
+			t.Errorf("%v != %v", want, value)
+		}
+	} else {
+		t.Error(err)
+	}
+}
+
 func TestDurationArray(t *testing.T) {
 	settings := getSettings()
 
@@ -482,6 +756,29 @@ func TestSize(t *testing.T) {
 	}
 }
 
+func TestSizeMode(t *testing.T) {
+	settings := getSettings()
+	settings.SizeMode = SizeStrict
+	var want int64 = 15 * 1000 * 1000 * 1000 * 1000
+	if value, err := settings.Size("values.size"); err == nil {
+		if want != value {
+			t.Errorf("%v != %v", want, value)
+		}
+	} else {
+		t.Error(err)
+	}
+
+	// plain integers are bytes
+	want = 1
+	if value, err := settings.Size("values.integer"); err == nil {
+		if want != value {
+			t.Errorf("%v != %v", want, value)
+		}
+	} else {
+		t.Error(err)
+	}
+}
+
 func TestSizeArray(t *testing.T) {
 	settings := getSettings()
 
@@ -517,3 +814,669 @@ func TestSizeMap(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestRegexp(t *testing.T) {
+	settings := getSettings()
+
+	// valid pattern
+	key := "regexp-array.1"
+	if value, err := settings.Regexp(key); err == nil {
+		if !value.MatchString("twoo") {
+			t.Errorf("%v does not match %s", value, "twoo")
+		}
+		if again, _ := settings.Regexp(key); again != value {
+			t.Errorf("%s was compiled twice", key)
+		}
+		view, _ := settings.Object("regexp-map")
+		if again, _ := view.Regexp("two"); again != value {
+			t.Errorf("%s was compiled again by a view", key)
+		}
+	} else {
+		t.Error(err)
+	}
+
+	// invalid pattern
+	key = "invalid-regexp"
+	if _, err := settings.Regexp(key); err == nil {
+		t.Errorf("key %s is valid", key)
+	} else if !strings.HasPrefix(err.Error(), "72:17: "+key+": ") {
+		t.Errorf("error does not name key %s: %s", key, err)
+	}
+
+	// invalid type
+	key = "values.integer"
+	if _, err := settings.Regexp(key); !errors.Is(err, TypeError) {
+		t.Errorf("key %s is valid", key)
+	}
+}
+
+func TestRegexpArray(t *testing.T) {
+	settings := getSettings()
+
+	// valid regexp array
+	want := []string{"^one$", "t[wo]+"}
+	if value, err := settings.RegexpArray("regexp-array"); err == nil {
+		have := make([]string, len(value))
+		for n, re := range value {
+			have[n] = re.String()
+		}
+		if !reflect.DeepEqual(want, have) {
+			t.Errorf("%v != %v", want, have)
+		}
+	} else {
+		t.Error(err)
+	}
+
+	// mixed array
+	key := "mixed-array"
+	if _, err := settings.RegexpArray(key); !errors.Is(err, TypeError) {
+		t.Errorf("key %s is valid", key)
+	}
+}
+
+func TestRegexpMap(t *testing.T) {
+	settings := getSettings()
+
+	// valid regexp map
+	want := map[string]string{"one": "^one$", "two": "t[wo]+"}
+	if value, err := settings.RegexpMap("regexp-map"); err == nil {
+		have := make(map[string]string, len(value))
+		for name, re := range value {
+			have[name] = re.String()
+		}
+		if !reflect.DeepEqual(want, have) {
+			t.Errorf("%v != %v", want, have)
+		}
+	} else {
+		t.Error(err)
+	}
+}
+
+func TestChoice(t *testing.T) {
+	settings := getSettings()
+	choices := Choices{
+		Allowed:    []string{"Value", "other"},
+		Aliases:    map[string]string{"a": "other"},
+		IgnoreCase: true,
+	}
+
+	// match ignoring case
+	want := "Value"
+	if value, err := settings.Choice("values.string", choices); err == nil {
+		if want != value {
+			t.Errorf("%v != %v", want, value)
+		}
+	} else {
+		t.Error(err)
+	}
+
+	// match an alias
+	want = "other"
+	if value, err := settings.Choice("mapping.a", Choices{Allowed: []string{"other"}, Aliases: map[string]string{"aye": "other"}}); err == nil {
+		if want != value {
+			t.Errorf("%v != %v", want, value)
+		}
+	} else {
+		t.Error(err)
+	}
+}
+
+func TestEnum(t *testing.T) {
+	settings := getSettings()
+
+	// valid value
+	want := "value"
+	if value, err := settings.Enum("values.string", "other", "value"); err == nil {
+		if want != value {
+			t.Errorf("%v != %v", want, value)
+		}
+	} else {
+		t.Error(err)
+	}
+
+	// invalid value
+	key := "mapping.a"
+	if _, err := settings.Enum(key, "bee", "see"); err == nil {
+		t.Errorf("key %s is valid", key)
+	} else if pe, ok := err.(*PathError); !ok || pe.Key != key {
+		t.Errorf("invalid error for key %s: %s", key, err)
+	} else if enumErr := (*EnumError)(nil); !errors.As(err, &enumErr) || enumErr.Value != "aye" {
+		t.Errorf("error for key %s does not wrap an EnumError: %s", key, err)
+	} else if want := `mapping.a: invalid value "aye", must be one of: bee, see`; !strings.HasSuffix(err.Error(), want) {
+		t.Errorf("%s != %s", want, err)
+	}
+
+	// missing value
+	key = "missing"
+	if _, err := settings.Enum(key, "value"); !errors.Is(err, KeyError) {
+		t.Errorf("key %s found", key)
+	}
+}
+
+func TestCoerce(t *testing.T) {
+	settings := getSettings()
+
+	// strict getters reject mismatched scalars
+	for _, key := range []string{"coerce.string", "coerce.float"} {
+		if _, err := settings.Int(key); !errors.Is(err, TypeError) {
+			t.Errorf("key %s is valid", key)
+		}
+	}
+	if _, err := settings.String("coerce.integer"); !errors.Is(err, TypeError) {
+		t.Errorf("key %s is valid", "coerce.integer")
+	}
+
+	settings.Coerce = true
+
+	// scalar conversions
+	for _, key := range []string{"coerce.string", "coerce.float", "coerce.integer"} {
+		if value, err := settings.Int(key); err != nil {
+			t.Errorf("key %s is invalid: %s", key, err)
+		} else if value != 8080 {
+			t.Errorf("%v != %v", 8080, value)
+		}
+		if value, err := settings.Float(key); err != nil {
+			t.Errorf("key %s is invalid: %s", key, err)
+		} else if value != 8080 {
+			t.Errorf("%v != %v", 8080, value)
+		}
+		if value, err := settings.String(key); err != nil {
+			t.Errorf("key %s is invalid: %s", key, err)
+		} else if value != "8080" {
+			t.Errorf("%v != %v", "8080", value)
+		}
+	}
+	if value, err := settings.String("coerce.bool"); err != nil || value != "true" {
+		t.Errorf("%v != %v (%v)", "true", value, err)
+	}
+
+	// invalid conversions
+	for _, key := range []string{"coerce.fraction", "coerce.word", "coerce.bool"} {
+		if _, err := settings.Int(key); !errors.Is(err, TypeError) {
+			t.Errorf("key %s is valid", key)
+		}
+	}
+
+	// arrays and maps
+	if value, err := settings.IntArray("coerce-array"); err == nil {
+		want := []int{1, 2, 3}
+		if !reflect.DeepEqual(want, value) {
+			t.Errorf("%v != %v", want, value)
+		}
+	} else {
+		t.Error(err)
+	}
+	if value, err := settings.StringMap("coerce-map"); err == nil {
+		want := map[string]string{"one": "1", "two": "2", "three": "3"}
+		if !reflect.DeepEqual(want, value) {
+			t.Errorf("%v != %v", want, value)
+		}
+	} else {
+		t.Error(err)
+	}
+
+	// objects inherit the mode
+	if object, err := settings.Object("coerce"); err == nil {
+		if value, err := object.Int("string"); err != nil || value != 8080 {
+			t.Errorf("%v != %v (%v)", 8080, value, err)
+		}
+	} else {
+		t.Error(err)
+	}
+}
+
+func TestPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-settings-")
+	if err != nil {
+		t.Fatal("failed to create temp dir for testing")
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "cert.pem"), []byte{}, 0644); err != nil {
+		t.Fatal("failed to create temp file for testing")
+	}
+
+	settings, _ := Parse([]byte(`cert: cert.pem
+dir: .
+missing: missing.pem
+absolute: /
+paths:
+- cert.pem
+- /`))
+	settings.File = filepath.Join(dir, "settings.yml")
+
+	// relative to the settings file
+	want := filepath.Join(dir, "cert.pem")
+	if value, err := settings.Path("cert", PathExists, PathIsFile, PathReadable); err == nil {
+		if want != value {
+			t.Errorf("%v != %v", want, value)
+		}
+	} else {
+		t.Error(err)
+	}
+
+	// absolute path
+	if value, err := settings.Path("absolute", PathIsDir); err == nil {
+		if value != "/" {
+			t.Errorf("%v != %v", "/", value)
+		}
+	} else {
+		t.Error(err)
+	}
+
+	// failed checks
+	if _, err := settings.Path("missing", PathExists); !os.IsNotExist(errors.Unwrap(err)) {
+		t.Errorf("key %s exists: %v", "missing", err)
+	}
+	if _, err := settings.Path("cert", PathIsDir); err == nil {
+		t.Errorf("key %s is a directory", "cert")
+	}
+	if _, err := settings.Path("dir", PathIsFile); err == nil {
+		t.Errorf("key %s is a file", "dir")
+	}
+
+	// unchecked missing path
+	if _, err := settings.Path("missing"); err != nil {
+		t.Error(err)
+	}
+
+	// array of paths
+	wantArray := []string{want, "/"}
+	if value, err := settings.PathArray("paths", PathExists); err == nil {
+		if !reflect.DeepEqual(wantArray, value) {
+			t.Errorf("%v != %v", wantArray, value)
+		}
+	} else {
+		t.Error(err)
+	}
+}
+
+func TestFileMode(t *testing.T) {
+	settings, _ := Parse([]byte(`quoted: "0640"
+prefixed: "0o640"
+unquoted: 0640
+octal: 0o640
+decimal: 644
+hex: 0x1a0
+invalid: true`))
+	settings.Set("set", 0640)
+
+	for _, key := range []string{"quoted", "prefixed", "unquoted", "octal"} {
+		if value, err := settings.FileMode(key); err == nil {
+			if value != 0640 {
+				t.Errorf("%v != %v", os.FileMode(0640), value)
+			}
+		} else {
+			t.Error(err)
+		}
+	}
+
+	for _, key := range []string{"decimal", "hex", "set", "invalid"} {
+		if _, err := settings.FileMode(key); !errors.Is(err, TypeError) {
+			t.Errorf("key %s is valid", key)
+		}
+	}
+}
+
+func TestUserID(t *testing.T) {
+	settings, _ := Parse([]byte(`numeric: 1234
+quoted: "1234"
+missing: go-settings-no-such-user`))
+
+	for _, key := range []string{"numeric", "quoted"} {
+		if value, err := settings.UserID(key); err == nil {
+			if value != 1234 {
+				t.Errorf("%v != %v", 1234, value)
+			}
+		} else {
+			t.Error(err)
+		}
+	}
+
+	if _, err := settings.UserID("missing"); err == nil {
+		t.Errorf("key %s is valid", "missing")
+	}
+}
+
+func TestGroupID(t *testing.T) {
+	settings, _ := Parse([]byte(`numeric: 1234
+missing: go-settings-no-such-group`))
+
+	if value, err := settings.GroupID("numeric"); err == nil {
+		if value != 1234 {
+			t.Errorf("%v != %v", 1234, value)
+		}
+	} else {
+		t.Error(err)
+	}
+
+	if _, err := settings.GroupID("missing"); err == nil {
+		t.Errorf("key %s is valid", "missing")
+	}
+}
+
+func TestRange(t *testing.T) {
+	settings, _ := Parse([]byte(`single: 3
+string: 8000-8100
+object:
+  min: 1
+  max: 10
+  exclude-max: true
+invalid:
+  min: 10
+  max: 1
+flag:
+  min: 1
+  max: 10
+  exclude-min: maybe
+ports: 1024-70000`))
+
+	type test struct {
+		key  string
+		want Range
+	}
+
+	tests := []test{
+		{"single", Range{Min: 3, Max: 3}},
+		{"string", Range{Min: 8000, Max: 8100}},
+		{"object", Range{Min: 1, Max: 10, ExcludeMax: true}},
+	}
+
+	for _, test := range tests {
+		if value, err := settings.Range(test.key); err == nil {
+			if test.want != value {
+				t.Errorf("%v != %v", test.want, value)
+			}
+		} else {
+			t.Error(err)
+		}
+	}
+
+	if _, err := settings.Range("invalid"); err == nil {
+		t.Errorf("key %s is valid", "invalid")
+	}
+	if _, err := settings.Range("missing"); !errors.Is(err, KeyError) {
+		t.Errorf("key %s found", "missing")
+	}
+	settings.StrictDflt = true
+	if _, err := settings.Range("flag"); !errors.Is(err, TypeError) {
+		t.Errorf("invalid exclude-min did not cause a TypeError: %v", err)
+	}
+
+	// port ranges
+	if value, err := settings.PortRange("string"); err != nil || value != (Range{Min: 8000, Max: 8100}) {
+		t.Errorf("%v != %v (%v)", Range{Min: 8000, Max: 8100}, value, err)
+	}
+	if _, err := settings.PortRange("ports"); err == nil {
+		t.Errorf("key %s is valid", "ports")
+	}
+}
+
+func TestRatio(t *testing.T) {
+	settings, _ := Parse([]byte(`percent: 75%
+decimal: 0.75
+fraction: 3/4
+one: 1
+large: 2
+ratios:
+- 25%
+- 0.5
+- 3/4
+weights:
+  canary: 10%
+  stable: 0.9`))
+
+	for _, key := range []string{"percent", "decimal", "fraction"} {
+		if value, err := settings.Ratio(key); err == nil {
+			if value != 0.75 {
+				t.Errorf("%v != %v", 0.75, value)
+			}
+		} else {
+			t.Error(err)
+		}
+	}
+
+	if value, err := settings.Ratio("one"); err != nil || value != 1 {
+		t.Errorf("%v != %v (%v)", 1, value, err)
+	}
+	if _, err := settings.Ratio("large"); err == nil {
+		t.Errorf("key %s is valid", "large")
+	}
+
+	wantArray := []float64{0.25, 0.5, 0.75}
+	if value, err := settings.RatioArray("ratios"); err == nil {
+		if !reflect.DeepEqual(wantArray, value) {
+			t.Errorf("%v != %v", wantArray, value)
+		}
+	} else {
+		t.Error(err)
+	}
+
+	wantMap := map[string]float64{"canary": 0.1, "stable": 0.9}
+	if value, err := settings.RatioMap("weights"); err == nil {
+		if !reflect.DeepEqual(wantMap, value) {
+			t.Errorf("%v != %v", wantMap, value)
+		}
+	} else {
+		t.Error(err)
+	}
+}
+
+func TestBytes(t *testing.T) {
+	settings, _ := Parse([]byte(`encoded: aGVsbG8=
+hex: hex:68656c6c6f
+binary: !!binary /wD+
+text: !!binary aGVsbG8gd29ybGQh
+invalid: not base64!
+number: 1`))
+
+	for _, key := range []string{"encoded", "hex"} {
+		if value, err := settings.Bytes(key); err == nil {
+			if string(value) != "hello" {
+				t.Errorf("%v != %v", "hello", string(value))
+			}
+		} else {
+			t.Error(err)
+		}
+	}
+
+	binary := map[string][]byte{
+		"binary": {0xff, 0x00, 0xfe},
+		"text":   []byte("hello world!"),
+	}
+	for key, want := range binary {
+		if value, err := settings.Bytes(key); err == nil {
+			if !reflect.DeepEqual(want, value) {
+				t.Errorf("%s: %v != %v", key, want, value)
+			}
+		} else {
+			t.Error(err)
+		}
+	}
+
+	// values set after parsing are decoded
+	settings.Set("text", "aGVsbG8=")
+	settings.Set("raw", []byte{1, 2, 3})
+	set := map[string][]byte{
+		"text": []byte("hello"),
+		"raw":  {1, 2, 3},
+	}
+	for key, want := range set {
+		if value, err := settings.Bytes(key); err == nil {
+			if !reflect.DeepEqual(want, value) {
+				t.Errorf("%s: %v != %v", key, want, value)
+			}
+		} else {
+			t.Error(err)
+		}
+	}
+
+	if _, err := settings.Bytes("invalid"); err == nil {
+		t.Errorf("key %s is valid", "invalid")
+	}
+	if _, err := settings.Bytes("number"); !errors.Is(err, TypeError) {
+		t.Errorf("key %s is valid", "number")
+	}
+}
+
+func TestCertificatesAndPrivateKey(t *testing.T) {
+	certPEM, keyPEM := getTestCertificate(t)
+	dir, err := ioutil.TempDir("", "go-settings-")
+	if err != nil {
+		t.Fatal("failed to create temp dir for testing")
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "cert.pem"), []byte(certPEM), 0644); err != nil {
+		t.Fatal("failed to create temp file for testing")
+	}
+
+	settings := New()
+	settings.File = filepath.Join(dir, "settings.yml")
+	settings.Set("tls.inline", certPEM)
+	settings.Set("tls.file", "cert.pem")
+	settings.Set("tls.bundle", []string{certPEM, "cert.pem"})
+	settings.Set("tls.missing", "missing.pem")
+	settings.Set("tls.key", keyPEM)
+
+	type test struct {
+		key   string
+		count int
+	}
+
+	tests := []test{
+		{"tls.inline", 1},
+		{"tls.file", 1},
+		{"tls.bundle", 2},
+	}
+
+	for _, test := range tests {
+		if certs, err := settings.Certificates(test.key); err == nil {
+			if len(certs) != test.count {
+				t.Errorf("%d != %d", test.count, len(certs))
+			} else if certs[0].Subject.CommonName != "go-settings" {
+				t.Errorf("%s != %s", "go-settings", certs[0].Subject.CommonName)
+			}
+		} else {
+			t.Error(err)
+		}
+	}
+
+	if _, err := settings.Certificates("tls.missing"); err == nil {
+		t.Errorf("key %s is valid", "tls.missing")
+	}
+	if _, err := settings.Certificates("tls.key"); err == nil {
+		t.Errorf("key %s is valid", "tls.key")
+	}
+
+	if _, err := settings.PrivateKey("tls.key"); err != nil {
+		t.Error(err)
+	}
+	if _, err := settings.PrivateKey("tls.inline"); err == nil {
+		t.Errorf("key %s is valid", "tls.inline")
+	}
+}
+
+func TestTimeOfDay(t *testing.T) {
+	settings, _ := Parse([]byte(`backup: "02:30"
+invalid: "25:00"`))
+
+	want := TimeOfDay{Hour: 2, Minute: 30}
+	if value, err := settings.TimeOfDay("backup"); err == nil {
+		if want != value {
+			t.Errorf("%v != %v", want, value)
+		}
+	} else {
+		t.Error(err)
+	}
+
+	if _, err := settings.TimeOfDay("invalid"); err == nil {
+		t.Errorf("key %s is valid", "invalid")
+	}
+}
+
+func TestWindowAndSchedule(t *testing.T) {
+	settings, _ := Parse([]byte(`maintenance:
+  days: [sat, sun]
+  from: "01:00"
+  to: "05:00"
+  tz: UTC
+uploads:
+- days: mon-fri
+  from: "22:00"
+  to: "02:00"
+- from: "12:00"
+  to: "13:00"
+invalid:
+  from: "01:00"
+  days: [caturday]`))
+
+	want := Window{
+		Days:     []time.Weekday{time.Saturday, time.Sunday},
+		From:     TimeOfDay{Hour: 1},
+		To:       TimeOfDay{Hour: 5},
+		Location: time.UTC,
+	}
+	if value, err := settings.Window("maintenance"); err == nil {
+		if !reflect.DeepEqual(want, value) {
+			t.Errorf("%v != %v", want, value)
+		}
+	} else {
+		t.Error(err)
+	}
+
+	if value, err := settings.Schedule("uploads"); err == nil {
+		if len(value) != 2 {
+			t.Errorf("%d != %d", 2, len(value))
+		} else if len(value[0].Days) != 5 || len(value[1].Days) != 0 {
+			t.Errorf("invalid days: %v", value)
+		}
+	} else {
+		t.Error(err)
+	}
+
+	if value, err := settings.Schedule("maintenance"); err != nil || len(value) != 1 {
+		t.Errorf("invalid schedule: %v (%v)", value, err)
+	}
+
+	if _, err := settings.Window("invalid"); err == nil {
+		t.Errorf("key %s is valid", "invalid")
+	}
+	if _, err := settings.Window("uploads"); !errors.Is(err, TypeError) {
+		t.Errorf("key %s is valid", "uploads")
+	}
+}
+
+func TestPathError(t *testing.T) {
+	settings := getSettings()
+	check := func(err error, want PathError) {
+		var pe *PathError
+		if !errors.As(err, &pe) {
+			t.Errorf("%v is not a PathError", err)
+			return
+		}
+		if !errors.Is(err, want.Err) {
+			t.Errorf("%v does not wrap %v", err, want.Err)
+		}
+		pe.Err = want.Err
+		if *pe != want {
+			t.Errorf("%+v != %+v", *pe, want)
+		}
+	}
+
+	_, err := settings.Int("mapping.a")
+	check(err, PathError{Op: "get", Key: "mapping.a", Want: "int", Got: "string", Value: "aye", Position: Position{Line: 4, Column: 6}, Err: TypeError})
+	_, err = settings.String("mapping.c.d")
+	check(err, PathError{Op: "get", Key: "mapping.c.d", Segment: "c", Err: KeyError})
+	_, err = settings.IntArray("mixed-array")
+	check(err, PathError{Op: "get", Key: "mixed-array.0", Want: "int", Got: "string", Value: "one", Position: Position{Line: 87, Column: 3}, Err: TypeError})
+
+	err = settings.Set("key.a", 1)
+	check(err, PathError{Op: "set", Key: "key.a", Segment: "a", Want: "object or array", Got: "string", Err: ObjectError})
+	err = settings.Append("string-array.x", 1)
+	check(err, PathError{Op: "append", Key: "string-array.x", Segment: "x", Err: IndexError})
+	err = settings.Delete("string-array.5")
+	check(err, PathError{Op: "delete", Key: "string-array.5", Segment: "5", Err: RangeError})
+
+	mapping, _ := settings.Object("mapping")
+	_, err = mapping.Int("b")
+	check(err, PathError{Op: "get", Key: "mapping.b", Want: "int", Got: "string", Value: "bee", Position: Position{Line: 5, Column: 6}, Err: TypeError})
+}
@@ -0,0 +1,360 @@
+package settings
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// KeyPath is a key split into its segments. Use it to build keys from
+// segments which may contain dots or other special characters. The String
+// method returns a key which may be passed to any getter or setter.
+type KeyPath []string
+
+// NewKeyPath returns a key path made up of the provided segments.
+func NewKeyPath(segments ...string) KeyPath {
+	return KeyPath(append([]string{}, segments...))
+}
+
+// Append returns a new key path with the segments appended.
+func (p KeyPath) Append(segments ...string) KeyPath {
+	path := make(KeyPath, 0, len(p)+len(segments))
+	path = append(path, p...)
+	return append(path, segments...)
+}
+
+// Return true if the segment must be quoted.
+func needsQuotes(segment string) bool {
+	return segment == "*" || segment == "**" || strings.ContainsAny(segment, `."\[`)
+}
+
+// Quote a segment if it contains special characters.
+func quoteSegment(segment string) string {
+	if !needsQuotes(segment) {
+		return segment
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range segment {
+		if r == '"' || r == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// String returns the path as a dotted key. Segments containing special
+// characters are quoted.
+func (p KeyPath) String() string {
+	quoted := make([]string, len(p))
+	for n, segment := range p {
+		quoted[n] = quoteSegment(segment)
+	}
+	return strings.Join(quoted, ".")
+}
+
+// Pointer returns the path as an RFC 6901 JSON Pointer.
+func (p KeyPath) Pointer() string {
+	var b strings.Builder
+	for _, segment := range p {
+		b.WriteByte('/')
+		segment = strings.Replace(segment, "~", "~0", -1)
+		b.WriteString(strings.Replace(segment, "/", "~1", -1))
+	}
+	return b.String()
+}
+
+// Return true if two keys are equal ignoring case and `_` and `-` separators.
+func foldKey(a, b string) bool {
+	for {
+		a, b = strings.TrimLeft(a, "_-"), strings.TrimLeft(b, "_-")
+		if a == "" || b == "" {
+			return a == b
+		}
+		ra, na := utf8.DecodeRuneInString(a)
+		rb, nb := utf8.DecodeRuneInString(b)
+		if ra != rb && unicode.ToLower(ra) != unicode.ToLower(rb) {
+			return false
+		}
+		a, b = a[na:], b[nb:]
+	}
+}
+
+// segment is a parsed key segment. Literal segments were quoted or escaped in
+// the key and are always matched by name. Other segments may be interpreted as
+// wildcards. Filter segments select elements of an array and have no name.
+type segment struct {
+	name    string
+	literal bool
+	filter  *filter
+}
+
+// Return the segments of a key path. Key path segments are always literal.
+func (p KeyPath) segments() []segment {
+	segments := make([]segment, len(p))
+	for n, name := range p {
+		segments[n] = segment{name: name, literal: true}
+	}
+	return segments
+}
+
+// ParseKey parses a dotted key into its segments. Segments are separated by
+// dots. A segment may be wrapped in double quotes to include dots, e.g.
+// `hosts."api.example.com".port`. Outside of quotes a backslash escapes the
+// following character, so `hosts.api\.example\.com.port` is equivalent.
+// Inside quotes a backslash escapes a double quote or a backslash. A
+// SyntaxError is returned for unterminated quotes and escapes.
+//
+// A segment may be followed by a filter in square brackets which selects
+// elements of an array of objects by the value of a field. The key
+// `backends[name=primary].url` refers to the URL of the first backend named
+// primary. Prefix the filter with `?` to select every matching element, so
+// `users[?enabled==true].name` refers to the names of all enabled users. The
+// operators `=`, `==` and `!=` are supported and values are compared by their
+// string form. Filters do not name a single key so ParseKey returns a
+// SyntaxError for keys which contain them.
+func ParseKey(key string) (KeyPath, error) {
+	segments, err := parseKeySegments(key)
+	if err != nil {
+		return nil, err
+	}
+	path := make(KeyPath, len(segments))
+	for n, segment := range segments {
+		if segment.filter != nil {
+			return nil, SyntaxError
+		}
+		path[n] = segment.name
+	}
+	return path, nil
+}
+
+// Return the index of the bracket closing the filter which opens at `start` or
+// -1 if the filter is unterminated.
+func filterEnd(key string, start int) int {
+	quoted := false
+	for i := start + 1; i < len(key); i++ {
+		switch c := key[i]; {
+		case quoted && c == '\\':
+			i++
+		case c == '"':
+			quoted = !quoted
+		case !quoted && c == ']':
+			return i
+		}
+	}
+	return -1
+}
+
+// Return the number of dots in a key and whether it is free of quotes,
+// escapes and filters.
+func plainKey(key string) (int, bool) {
+	dots := 0
+	for i := 0; i < len(key); i++ {
+		switch key[i] {
+		case '"', '\\', '[':
+			return 0, false
+		case '.':
+			dots++
+		}
+	}
+	return dots, true
+}
+
+// Parse a dotted key into segments.
+func parseKeySegments(key string) ([]segment, error) {
+	// plain keys are split on dots without building each name
+	if dots, ok := plainKey(key); ok {
+		segments := make([]segment, 0, dots+1)
+		for {
+			dot := strings.IndexByte(key, '.')
+			if dot == -1 {
+				return append(segments, segment{name: key}), nil
+			}
+			segments = append(segments, segment{name: key[:dot]})
+			key = key[dot+1:]
+		}
+	}
+
+	var segments []segment
+	var name strings.Builder
+	quoted, literal, filtered := false, false, false
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if filtered && c != '.' && c != '[' {
+			return nil, SyntaxError
+		}
+		switch {
+		case c == '\\':
+			if i+1 >= len(key) {
+				return nil, SyntaxError
+			}
+			i++
+			name.WriteByte(key[i])
+			literal = true
+		case quoted && c == '"':
+			quoted = false
+			if i+1 < len(key) && key[i+1] != '.' && key[i+1] != '[' {
+				return nil, SyntaxError
+			}
+		case quoted:
+			name.WriteByte(c)
+		case c == '"':
+			if name.Len() != 0 {
+				return nil, SyntaxError
+			}
+			quoted, literal = true, true
+		case c == '[':
+			end := filterEnd(key, i)
+			if end == -1 {
+				return nil, SyntaxError
+			}
+			f, err := parseFilter(key[i+1 : end])
+			if err != nil {
+				return nil, err
+			}
+			if name.Len() != 0 || literal {
+				segments = append(segments, segment{name: name.String(), literal: literal})
+			} else if !filtered {
+				return nil, SyntaxError
+			}
+			segments = append(segments, segment{filter: f})
+			name.Reset()
+			literal, filtered = false, true
+			i = end
+		case c == '.':
+			if !filtered {
+				segments = append(segments, segment{name: name.String(), literal: literal})
+			}
+			name.Reset()
+			literal, filtered = false, false
+		default:
+			name.WriteByte(c)
+		}
+	}
+	if quoted {
+		return nil, SyntaxError
+	}
+	if filtered {
+		return segments, nil
+	}
+	return append(segments, segment{name: name.String(), literal: literal}), nil
+}
+
+// ParsePointer parses an RFC 6901 JSON Pointer such as `/hosts/api.example.com`
+// into its segments. The empty pointer refers to the whole document and
+// results in an empty path.
+func ParsePointer(pointer string) (KeyPath, error) {
+	if pointer == "" {
+		return KeyPath{}, nil
+	}
+	if pointer[0] != '/' {
+		return nil, SyntaxError
+	}
+	segments := strings.Split(pointer[1:], "/")
+	path := make(KeyPath, len(segments))
+	for n, segment := range segments {
+		for i := 0; i < len(segment); i++ {
+			if segment[i] != '~' {
+				continue
+			}
+			if i+1 >= len(segment) || (segment[i+1] != '0' && segment[i+1] != '1') {
+				return nil, SyntaxError
+			}
+			i++
+		}
+		segment = strings.Replace(segment, "~1", "/", -1)
+		path[n] = strings.Replace(segment, "~0", "~", -1)
+	}
+	return path, nil
+}
+
+// Parse a JSON Pointer into segments. JSON Pointer segments are never
+// literal.
+func parsePointerSegments(pointer string) ([]segment, error) {
+	path, err := ParsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	segments := make([]segment, len(path))
+	for n, name := range path {
+		segments[n] = segment{name: name}
+	}
+	return segments, nil
+}
+
+// compiledKey holds a key parsed with both key syntaxes.
+type compiledKey struct {
+	path       []segment
+	err        error
+	pointer    []segment
+	pointerErr error
+}
+
+// compiled keys by key string
+var compiledKeys sync.Map
+
+// CompileKey parses a key once so that later calls to any getter or setter
+// with the same key skip parsing. Scalar getters called with a compiled key do
+// not allocate. The key is returned unchanged so it may be stored and passed
+// to the getters as is:
+//
+//	var portKey = settings.MustCompileKey("db.port")
+//	port, err := s.Int(portKey)
+//
+// Keys are compiled for both the dotted and JSON Pointer syntax and a
+// SyntaxError is returned only if the key is invalid in both. Compiled keys
+// are kept for the life of the program so only compile a fixed set of keys.
+func CompileKey(key string) (string, error) {
+	c := &compiledKey{}
+	c.path, c.err = parseKeySegments(key)
+	c.pointer, c.pointerErr = parsePointerSegments(key)
+	if c.err != nil && c.pointerErr != nil {
+		return "", c.err
+	}
+	compiledKeys.Store(key, c)
+	return key, nil
+}
+
+// MustCompileKey is like CompileKey but panics if the key is invalid.
+func MustCompileKey(key string) string {
+	if _, err := CompileKey(key); err != nil {
+		panic(fmt.Sprintf("settings: invalid key %q: %s", key, err))
+	}
+	return key
+}
+
+// Parse a key using the key syntax of the settings object. Compiled keys are
+// not parsed again. The returned segments must not be modified.
+func (s *Settings) parseKey(key string) ([]segment, error) {
+	if c, ok := compiledKeys.Load(key); ok {
+		if s.Pointers {
+			return c.(*compiledKey).pointer, c.(*compiledKey).pointerErr
+		}
+		return c.(*compiledKey).path, c.(*compiledKey).err
+	}
+	if s.Pointers {
+		return parsePointerSegments(key)
+	}
+	return parseKeySegments(key)
+}
+
+// Format a key path using the key syntax of the settings object.
+func (s *Settings) formatKey(path KeyPath) string {
+	if s.Pointers {
+		return path.Pointer()
+	}
+	return path.String()
+}
+
+// Append a segment to a key using the key syntax of the settings object.
+func (s *Settings) joinKey(key string, segment interface{}) string {
+	str := fmt.Sprintf("%v", segment)
+	if s.Pointers {
+		return key + NewKeyPath(str).Pointer()
+	}
+	return key + "." + quoteSegment(str)
+}
@@ -0,0 +1,245 @@
+package settings
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseKey(t *testing.T) {
+	type test struct {
+		key  string
+		path KeyPath
+	}
+
+	tests := []test{
+		{"", KeyPath{""}},
+		{"a", KeyPath{"a"}},
+		{"a.b.c", KeyPath{"a", "b", "c"}},
+		{"a..b", KeyPath{"a", "", "b"}},
+		{`hosts."api.example.com".port`, KeyPath{"hosts", "api.example.com", "port"}},
+		{`hosts.api\.example\.com.port`, KeyPath{"hosts", "api.example.com", "port"}},
+		{`"a\"b\\c"`, KeyPath{`a"b\c`}},
+		{`a.""`, KeyPath{"a", ""}},
+		{`"com.example.name"`, KeyPath{"com.example.name"}},
+	}
+
+	for _, test := range tests {
+		if path, err := ParseKey(test.key); err != nil {
+			t.Errorf("error parsing %s: %s", test.key, err)
+		} else if !reflect.DeepEqual(path, test.path) {
+			t.Errorf("path is invalid: %#v != %#v", path, test.path)
+		}
+	}
+
+	errors := []string{`"a`, `a\`, `a"b"`, `"a"b`, `a."b`}
+	for _, key := range errors {
+		if _, err := ParseKey(key); err != SyntaxError {
+			t.Errorf("no error parsing %s", key)
+		}
+	}
+}
+
+func TestParsePointer(t *testing.T) {
+	type test struct {
+		pointer string
+		path    KeyPath
+	}
+
+	tests := []test{
+		{"", KeyPath{}},
+		{"/", KeyPath{""}},
+		{"/a/b", KeyPath{"a", "b"}},
+		{"/hosts/api.example.com/port", KeyPath{"hosts", "api.example.com", "port"}},
+		{"/a~1b/c~0d", KeyPath{"a/b", "c~d"}},
+		{"/~01", KeyPath{"~1"}},
+	}
+
+	for _, test := range tests {
+		if path, err := ParsePointer(test.pointer); err != nil {
+			t.Errorf("error parsing %s: %s", test.pointer, err)
+		} else if !reflect.DeepEqual(path, test.path) {
+			t.Errorf("path is invalid: %#v != %#v", path, test.path)
+		}
+	}
+
+	errors := []string{"a/b", "/a~", "/a~2"}
+	for _, pointer := range errors {
+		if _, err := ParsePointer(pointer); err != SyntaxError {
+			t.Errorf("no error parsing %s", pointer)
+		}
+	}
+}
+
+func TestKeyPath(t *testing.T) {
+	path := NewKeyPath("hosts", "api.example.com").Append("port")
+	want := KeyPath{"hosts", "api.example.com", "port"}
+	if !reflect.DeepEqual(path, want) {
+		t.Errorf("%v != %v", want, path)
+	}
+
+	if have := path.String(); have != `hosts."api.example.com".port` {
+		t.Errorf("%s != %s", `hosts."api.example.com".port`, have)
+	}
+	if have := path.Pointer(); have != "/hosts/api.example.com/port" {
+		t.Errorf("%s != %s", "/hosts/api.example.com/port", have)
+	}
+
+	// round trip special characters
+	paths := []KeyPath{
+		{"a", `b"c`, `d\e`, "f.g"},
+		{"a/b", "~c"},
+	}
+	for _, path := range paths {
+		if parsed, err := ParseKey(path.String()); err != nil || !reflect.DeepEqual(parsed, path) {
+			t.Errorf("%v != %v (%v)", path, parsed, err)
+		}
+		if parsed, err := ParsePointer(path.Pointer()); err != nil || !reflect.DeepEqual(parsed, path) {
+			t.Errorf("%v != %v (%v)", path, parsed, err)
+		}
+	}
+}
+
+func TestFoldKey(t *testing.T) {
+	equal := [][2]string{
+		{"max_conns", "maxConns"},
+		{"max-conns", "MAX_CONNS"},
+		{"_a_", "A"},
+		{"", "-"},
+		{"Größe", "GRÖßE"},
+	}
+	for _, keys := range equal {
+		if !foldKey(keys[0], keys[1]) {
+			t.Errorf("%s != %s", keys[0], keys[1])
+		}
+	}
+
+	different := [][2]string{
+		{"max_conns", "max_con"},
+		{"a.b", "ab"},
+		{"a", ""},
+	}
+	for _, keys := range different {
+		if foldKey(keys[0], keys[1]) {
+			t.Errorf("%s == %s", keys[0], keys[1])
+		}
+	}
+}
+
+func TestCompileKey(t *testing.T) {
+	settings, _ := Parse([]byte(`db:
+  host: localhost
+  port: 5432
+hosts:
+  api.example.com:
+    port: 8443`))
+
+	tests := map[string]int{
+		MustCompileKey("db.port"):                      5432,
+		MustCompileKey(`hosts."api.example.com".port`): 8443,
+	}
+	for key, want := range tests {
+		if value, err := settings.Int(key); err != nil || value != want {
+			t.Errorf("%s: %v != %v (%v)", key, value, want, err)
+		}
+	}
+
+	key := MustCompileKey("/db/port")
+	if value, err := settings.Raw(key); !errors.Is(err, KeyError) {
+		t.Errorf("%s: found %v (%v)", key, value, err)
+	}
+	settings.Pointers = true
+	if value, err := settings.Int(key); err != nil || value != 5432 {
+		t.Errorf("%s: %v != %v (%v)", key, value, 5432, err)
+	}
+	if _, err := settings.Int(MustCompileKey("db.port")); !errors.Is(err, SyntaxError) {
+		t.Errorf("dotted key valid as pointer: %v", err)
+	}
+	settings.Pointers = false
+
+	key = MustCompileKey("db.user")
+	if err := settings.Set(key, "admin"); err != nil {
+		t.Error(err)
+	}
+	if value, err := settings.String(key); err != nil || value != "admin" {
+		t.Errorf("%s: %v != %v (%v)", key, value, "admin", err)
+	}
+
+	if _, err := CompileKey(`db."port`); err != SyntaxError {
+		t.Errorf("invalid key compiled: %v", err)
+	}
+}
+
+func TestCompileKeyAllocs(t *testing.T) {
+	settings, _ := Parse([]byte(`db:
+  host: localhost
+  port: 5432
+  timeout: 5s
+  enabled: true`))
+	host := MustCompileKey("db.host")
+	port := MustCompileKey("db.port")
+	enabled := MustCompileKey("db.enabled")
+
+	allocs := testing.AllocsPerRun(100, func() {
+		settings.String(host)
+		settings.Int(port)
+		settings.Bool(enabled)
+		settings.IntDflt(port, 0)
+	})
+	if allocs != 0 {
+		t.Errorf("compiled key lookups allocated %v times", allocs)
+	}
+}
+
+func TestPlainKeyAllocs(t *testing.T) {
+	allocs := testing.AllocsPerRun(100, func() {
+		parseKeySegments("server.http.port")
+	})
+	if allocs != 1 {
+		t.Errorf("plain key parsing allocated %v times", allocs)
+	}
+}
+
+func benchmarkSettings() *Settings {
+	settings, _ := Parse([]byte(`server:
+  http:
+    listen: 0.0.0.0
+    port: 8080`))
+	return settings
+}
+
+func BenchmarkInt(b *testing.B) {
+	settings := benchmarkSettings()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		settings.Int("server.http.port")
+	}
+}
+
+func BenchmarkIntCompiled(b *testing.B) {
+	settings := benchmarkSettings()
+	key := MustCompileKey("server.http.port")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		settings.Int(key)
+	}
+}
+
+func BenchmarkString(b *testing.B) {
+	settings := benchmarkSettings()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		settings.String("server.http.listen")
+	}
+}
+
+func BenchmarkStringCompiled(b *testing.B) {
+	settings := benchmarkSettings()
+	key := MustCompileKey("server.http.listen")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		settings.String(key)
+	}
+}
@@ -0,0 +1,143 @@
+package settings
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+var base64Encodings = []*base64.Encoding{
+	base64.StdEncoding,
+	base64.RawStdEncoding,
+	base64.URLEncoding,
+	base64.RawURLEncoding,
+}
+
+// Decode binary data from a string. Strings prefixed with `hex:` are hex
+// encoded and strings prefixed with `base64:` are base64 encoded. Strings
+// without a prefix are decoded as base64 (standard or URL safe, with or without
+// padding).
+func ParseBytes(s string) ([]byte, error) {
+	str := strings.TrimSpace(s)
+	lower := strings.ToLower(str)
+	switch {
+	case strings.HasPrefix(lower, "hex:"):
+		return hex.DecodeString(str[4:])
+	case strings.HasPrefix(lower, "base64:"):
+		str = str[7:]
+	}
+
+	str = strings.Join(strings.Fields(str), "")
+	for _, encoding := range base64Encodings {
+		if data, err := encoding.DecodeString(str); err == nil {
+			return data, nil
+		}
+	}
+	return nil, errors.New("invalid base64 data")
+}
+
+// Return the data of a YAML `!!binary` value at `key`. yaml.v2 decodes these
+// to a string so the tag is read from the source, which must still decode to
+// the same data.
+func (s *Settings) binaryValue(key, value string) ([]byte, bool) {
+	node := s.sourceNode(key)
+	if node == nil || node.tag != "!!binary" {
+		return nil, false
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(node.text), ""))
+	if err != nil || string(data) != value {
+		return nil, false
+	}
+	return data, true
+}
+
+// Return true if the string contains an inline PEM block.
+func isPEM(s string) bool {
+	return strings.Contains(s, "-----BEGIN ")
+}
+
+// Read PEM data from the value, which is either inline PEM or a file path.
+// Paths are resolved with the same rules as the Path getter.
+func (s *Settings) getPEMData(key string, value interface{}) ([]byte, error) {
+	str, ok := value.(string)
+	if !ok {
+		return nil, typeError("PEM data", value)
+	}
+	if isPEM(str) {
+		return []byte(str), nil
+	}
+	path, err := s.getPathValue(key, str, nil)
+	if err != nil {
+		return nil, err
+	}
+	if data, err := ioutil.ReadFile(path); err == nil {
+		return data, nil
+	} else {
+		return nil, s.keyError(key, err)
+	}
+}
+
+// Parse all of the certificates in a PEM encoded document.
+func ParseCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+			certs = append(certs, cert)
+		} else {
+			return nil, err
+		}
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no certificates found")
+	}
+	return certs, nil
+}
+
+// Parse the first private key in a PEM encoded document. PKCS #1, PKCS #8 and
+// SEC 1 (EC) keys are supported. Encrypted keys are not.
+func ParsePrivateKey(data []byte) (crypto.Signer, error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		var key interface{}
+		var err error
+		switch block.Type {
+		case "RSA PRIVATE KEY":
+			key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		case "EC PRIVATE KEY":
+			key, err = x509.ParseECPrivateKey(block.Bytes)
+		case "PRIVATE KEY":
+			key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		case "ENCRYPTED PRIVATE KEY":
+			return nil, errors.New("encrypted private keys are not supported")
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if signer, ok := key.(crypto.Signer); ok {
+			return signer, nil
+		}
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	return nil, errors.New("no private key found")
+}
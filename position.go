@@ -0,0 +1,198 @@
+package settings
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"strconv"
+)
+
+// Position is the location of a value in the source the settings were parsed
+// from. Lines and columns start at 1.
+type Position struct {
+	File   string
+	Line   int
+	Column int
+}
+
+// IsValid returns true if the position is known.
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+// String returns the position as `file:line:column`, or `line:column` if the
+// file is not known.
+func (p Position) String() string {
+	if !p.IsValid() {
+		return ""
+	}
+	if p.File == "" {
+		return fmt.Sprintf("%d:%d", p.Line, p.Column)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
+// posNode records the position, tag and scalar text of a value and the nodes
+// of its children. Children are keyed by the string form of their map key or
+// by their array index.
+type posNode struct {
+	pos      Position
+	tag      string
+	text     string
+	children map[string]*posNode
+}
+
+// Return the named child of the node, creating it if necessary.
+func (n *posNode) child(name string) *posNode {
+	if n.children == nil {
+		n.children = make(map[string]*posNode)
+	}
+	child, ok := n.children[name]
+	if !ok {
+		child = &posNode{}
+		n.children[name] = child
+	}
+	return child
+}
+
+// Build the positions of the values in YAML source from a yaml.v3 node tree.
+// The values themselves are decoded by yaml.v2, so map keys are named by the
+// value yaml.v2 decodes them to. Nil is returned if the source cannot be
+// parsed as a node tree.
+func scanPositions(data []byte) *posNode {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return nil
+	}
+	root := &posNode{}
+	addPositions(root, doc.Content[0])
+	return root
+}
+
+// Record the tag and text of a YAML node and the positions of its children.
+func addPositions(pos *posNode, node *yaml.Node) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	pos.tag = node.Tag
+	if node.Kind == yaml.ScalarNode {
+		pos.text = node.Value
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		var merges []*yaml.Node
+		for n := 0; n+1 < len(node.Content); n += 2 {
+			key, value := node.Content[n], node.Content[n+1]
+			if key.Kind != yaml.ScalarNode {
+				continue
+			}
+			if key.Tag == "!!merge" {
+				merges = append(merges, value)
+				continue
+			}
+			child := pos.child(keyName(key))
+			child.pos = Position{Line: key.Line, Column: key.Column}
+			if value.Line == key.Line {
+				child.pos = Position{Line: value.Line, Column: value.Column}
+			}
+			addPositions(child, value)
+		}
+		// merged keys do not replace those of the mapping itself
+		for _, merge := range merges {
+			if merge.Kind == yaml.AliasNode {
+				merge = merge.Alias
+			}
+			sources := []*yaml.Node{merge}
+			if merge.Kind == yaml.SequenceNode {
+				sources = merge.Content
+			}
+			for _, source := range sources {
+				merged := &posNode{}
+				addPositions(merged, source)
+				for name, child := range merged.children {
+					if _, ok := pos.children[name]; !ok {
+						*pos.child(name) = *child
+					}
+				}
+			}
+		}
+	case yaml.SequenceNode:
+		for n, item := range node.Content {
+			child := pos.child(strconv.Itoa(n))
+			child.pos = Position{Line: item.Line, Column: item.Column}
+			addPositions(child, item)
+		}
+	}
+}
+
+// Return the name of a mapping key as it is decoded by yaml.v2.
+func keyName(key *yaml.Node) string {
+	if key.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle|yaml.LiteralStyle|yaml.FoldedStyle) != 0 ||
+		(key.Style&yaml.TaggedStyle != 0 && key.Tag == "!!str") {
+		return key.Value
+	}
+	if value, ok := scalarKey(key.Value); ok {
+		return fmt.Sprint(value)
+	}
+	return key.Value
+}
+
+// Position returns the location in the source of the value at `key`. The
+// position is that of the value if it follows its key on the same line and of
+// the key otherwise. It is only known for settings returned by Parse, Read and
+// Load and does not reflect changes made after parsing. The second result is
+// false if the position is not known.
+func (s *Settings) Position(key string) (Position, bool) {
+	node := s.sourceNode(key)
+	if node == nil || !node.pos.IsValid() {
+		return Position{}, false
+	}
+	pos := node.pos
+	pos.File = s.Root().File
+	return pos, true
+}
+
+// Return the source node of the value at `key` or nil if it is not known.
+func (s *Settings) sourceNode(key string) *posNode {
+	root := s.Root()
+	if root.positions == nil {
+		return nil
+	}
+	path, err := s.parseKey(s.FullKey(key))
+	if err != nil {
+		return nil
+	}
+
+	node, value := root.positions, interface{}(root.Values)
+	for _, seg := range path {
+		var name string
+		switch obj := value.(type) {
+		case map[interface{}]interface{}:
+			if seg.filter != nil {
+				return nil
+			}
+			mapKey, err := s.mapKey(obj, seg.name)
+			if err != nil {
+				return nil
+			}
+			name, value = fmt.Sprint(mapKey), obj[mapKey]
+		case []interface{}:
+			var n int
+			if seg.filter != nil {
+				indices := seg.filter.selectIndices(s, obj)
+				if seg.filter.all || len(indices) == 0 {
+					return nil
+				}
+				n = indices[0]
+			} else if n, err = arrayIndex(seg.name, len(obj)); err != nil {
+				return nil
+			}
+			name, value = strconv.Itoa(n), obj[n]
+		default:
+			return nil
+		}
+		if node = node.children[name]; node == nil {
+			return nil
+		}
+	}
+	return node
+}
@@ -0,0 +1,169 @@
+package settings
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const positionSource = `# comment
+name: app
+server:
+  host: localhost   # trailing comment
+  port: 80a
+  tls:
+    cert: server.pem
+"a.b": dotted
+404: missing.html
+yes: affirmative
+motd: |
+  fake: key
+  - fake item
+list:
+- one
+-
+- - nested
+  - pair
+servers:
+  - name: web
+    port: 8080
+  - name: db
+    tags: [a,
+      b]
+quoted: "multi
+  line: value"
+flow: {a: 1}
+anchor: &base
+  x: 1
+after: done
+`
+
+func TestPosition(t *testing.T) {
+	settings, err := Parse([]byte(positionSource))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]Position{
+		"name":                   {Line: 2, Column: 7},
+		"server":                 {Line: 3, Column: 1},
+		"server.host":            {Line: 4, Column: 9},
+		"server.port":            {Line: 5, Column: 9},
+		"server.tls.cert":        {Line: 7, Column: 11},
+		`"a.b"`:                  {Line: 8, Column: 8},
+		"404":                    {Line: 9, Column: 6},
+		"yes":                    {Line: 10, Column: 6},
+		"motd":                   {Line: 11, Column: 7},
+		"list.0":                 {Line: 15, Column: 3},
+		"list.1":                 {Line: 16, Column: 2},
+		"list.2.0":               {Line: 17, Column: 5},
+		"list.-1.1":              {Line: 18, Column: 5},
+		"servers.1.name":         {Line: 22, Column: 11},
+		"servers[name=db]":       {Line: 22, Column: 5},
+		"servers.1.tags":         {Line: 23, Column: 11},
+		"quoted":                 {Line: 25, Column: 9},
+		"flow":                   {Line: 27, Column: 7},
+		"anchor.x":               {Line: 29, Column: 6},
+		"after":                  {Line: 30, Column: 8},
+		"servers.0.port":         {Line: 21, Column: 11},
+		"server.tls":             {Line: 6, Column: 3},
+		"servers.-2":             {Line: 20, Column: 5},
+		"list":                   {Line: 14, Column: 1},
+		"servers":                {Line: 19, Column: 1},
+		"servers.0.name":         {Line: 20, Column: 11},
+		"servers[name=web].port": {Line: 21, Column: 11},
+		"flow.a":                 {Line: 27, Column: 11},
+		"servers.1.tags.0":       {Line: 23, Column: 12},
+		"servers.1.tags.1":       {Line: 24, Column: 7},
+	}
+	for key, want := range tests {
+		if have, ok := settings.Position(key); !ok || have != want {
+			t.Errorf("%s: %v != %v", key, have, want)
+		}
+	}
+
+	for _, key := range []string{"missing", "flow.b", "servers.1.tags.2", "servers[?name==db]", "list.1:2"} {
+		if pos, ok := settings.Position(key); ok {
+			t.Errorf("%s: position %v found", key, pos)
+		}
+	}
+
+	server, _ := settings.Object("server")
+	if pos, ok := server.Position("tls.cert"); !ok || pos != (Position{Line: 7, Column: 11}) {
+		t.Errorf("view position %v is invalid", pos)
+	}
+	if pos, ok := New().Position("name"); ok {
+		t.Errorf("position %v found in new settings", pos)
+	}
+}
+
+func TestPositionError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "settings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "app.yml")
+	if err := ioutil.WriteFile(path, []byte(positionSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	settings, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = settings.Int("server.port")
+	if want := path + `:5:9: server.port: cannot convert "80a" to int`; err == nil || err.Error() != want {
+		t.Errorf("%v != %s", err, want)
+	}
+	var pe *PathError
+	if !errors.As(err, &pe) || pe.Position != (Position{File: path, Line: 5, Column: 9}) {
+		t.Errorf("error %v has an invalid position", err)
+	}
+	if _, err := settings.Int("server.missing"); err == nil || strings.HasPrefix(err.Error(), path) {
+		t.Errorf("missing key error %v has a position", err)
+	}
+	if _, err := settings.Enum("name", "web", "db"); err == nil || !strings.HasPrefix(err.Error(), path+":2:7: name: ") {
+		t.Errorf("enum error %v does not have a position", err)
+	}
+}
+
+func TestPositionAnchors(t *testing.T) {
+	settings, err := Parse([]byte(`base: &base
+  host: localhost
+  port: 80
+web:
+  <<: *base
+  port: 8080
+items:
+- &item
+  name: a
+- *item
+tabbed:	value
+? complex
+: key
+after: done
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]Position{
+		"web.host":     {Line: 2, Column: 9},
+		"web.port":     {Line: 6, Column: 9},
+		"items.0.name": {Line: 9, Column: 9},
+		"items.1":      {Line: 10, Column: 3},
+		"items.1.name": {Line: 9, Column: 9},
+		"tabbed":       {Line: 11, Column: 9},
+		"complex":      {Line: 12, Column: 3},
+		"after":        {Line: 14, Column: 8},
+	}
+	for key, want := range tests {
+		if have, ok := settings.Position(key); !ok || have != want {
+			t.Errorf("%s: %v != %v", key, have, want)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package settings
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Match is a value found by Query along with its concrete key.
+type Match struct {
+	Key   string
+	Value interface{}
+}
+
+// Return the children of an object or array along with their names. Object
+// children are sorted by name.
+func getChildren(value interface{}) (names []string, children []interface{}) {
+	switch value.(type) {
+	case map[interface{}]interface{}:
+		mapping := value.(map[interface{}]interface{})
+		keys := make([]interface{}, 0, len(mapping))
+		for key := range mapping {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+		})
+		for _, key := range keys {
+			names = append(names, fmt.Sprint(key))
+			children = append(children, mapping[key])
+		}
+	case []interface{}:
+		for n, child := range value.([]interface{}) {
+			names = append(names, strconv.Itoa(n))
+			children = append(children, child)
+		}
+	}
+	return
+}
+
+// Recursively collect the values matching the pattern.
+func (s *Settings) query(value interface{}, pattern []segment, path KeyPath, seen map[string]bool, matches *[]Match) {
+	if len(pattern) == 0 {
+		key := s.formatKey(path)
+		if !seen[key] {
+			seen[key] = true
+			*matches = append(*matches, Match{Key: key, Value: value})
+		}
+		return
+	}
+
+	seg := pattern[0]
+	switch {
+	case !seg.literal && seg.name == "**":
+		s.query(value, pattern[1:], path, seen, matches)
+		names, children := getChildren(value)
+		for n, child := range children {
+			s.query(child, pattern, path.Append(names[n]), seen, matches)
+		}
+	case !seg.literal && seg.name == "*":
+		names, children := getChildren(value)
+		for n, child := range children {
+			s.query(child, pattern[1:], path.Append(names[n]), seen, matches)
+		}
+	default:
+		names, children := s.selectChildren(value, seg)
+		for n, child := range children {
+			s.query(child, pattern[1:], path.Append(names[n]), seen, matches)
+		}
+	}
+}
+
+// Return the children of a value selected by a single pattern segment along
+// with their names.
+func (s *Settings) selectChildren(value interface{}, seg segment) (names []string, children []interface{}) {
+	switch {
+	case seg.filter != nil:
+		if items, ok := value.([]interface{}); ok {
+			for _, n := range seg.filter.selectIndices(s, items) {
+				names = append(names, strconv.Itoa(n))
+				children = append(children, items[n])
+			}
+		}
+	case !seg.literal && seg.name == "*":
+		return getChildren(value)
+	default:
+		if child, err := s.getChild(value, seg); err == nil {
+			name := seg.name
+			if items, ok := value.([]interface{}); ok {
+				if n, err := arrayIndex(name, len(items)); err == nil {
+					name = strconv.Itoa(n)
+				}
+			}
+			names, children = []string{name}, []interface{}{child}
+		}
+	}
+	return
+}
+
+// Return true if the path contains a filter which selects every matching
+// element.
+func projects(path []segment) bool {
+	for _, seg := range path {
+		if seg.filter != nil && seg.filter.all {
+			return true
+		}
+	}
+	return false
+}
+
+// Evaluate a path containing a `[?...]` filter. The array the first such
+// filter applies to must exist.
+func (s *Settings) project(path []segment) ([]Match, error) {
+	matches := []Match{}
+	s.query(s.Values, path, KeyPath{}, map[string]bool{}, &matches)
+	if len(matches) == 0 {
+		n := 0
+		for path[n].filter == nil || !path[n].filter.all {
+			n++
+		}
+		if value, err := s.rawPath(path[:n]); err != nil {
+			return nil, err
+		} else if _, ok := value.([]interface{}); !ok {
+			return nil, typeError("array", value)
+		}
+	}
+	return matches, nil
+}
+
+// Query returns every value matching the pattern along with its concrete key.
+// Patterns use the same syntax as other keys with two additions: a `*`
+// segment matches any single object key or array index and a `**` segment
+// matches zero or more levels. Filters select the matching array elements.
+// For example `servers.*.port` matches the port of every server and
+// `**.timeout` matches every timeout in the settings. Quote a segment to match
+// a literal `*` key. Matches are returned depth first with object keys in
+// sorted order. No matches is not an error.
+func (s *Settings) Query(pattern string) ([]Match, error) {
+	path, err := s.parseKey(pattern)
+	if err != nil {
+		return nil, s.keyError(pattern, err)
+	}
+	matches := []Match{}
+	s.query(s.Values, path, KeyPath{}, map[string]bool{}, &matches)
+	return matches, nil
+}
+
+// Query for string values. The result maps each concrete key to its value.
+func (s *Settings) QueryStrings(pattern string) (map[string]string, error) {
+	matches, err := s.Query(pattern)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(matches))
+	for _, match := range matches {
+		if value, err := s.getStringValue(match.Value); err == nil {
+			values[match.Key] = value
+		} else {
+			return nil, s.keyError(match.Key, err)
+		}
+	}
+	return values, nil
+}
+
+// Query for integer values. The result maps each concrete key to its value.
+func (s *Settings) QueryInts(pattern string) (map[string]int, error) {
+	matches, err := s.Query(pattern)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]int, len(matches))
+	for _, match := range matches {
+		if value, err := s.getIntValue(match.Value); err == nil {
+			values[match.Key] = value
+		} else {
+			return nil, s.keyError(match.Key, err)
+		}
+	}
+	return values, nil
+}
+
+// Query for float values. The result maps each concrete key to its value.
+func (s *Settings) QueryFloats(pattern string) (map[string]float64, error) {
+	matches, err := s.Query(pattern)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]float64, len(matches))
+	for _, match := range matches {
+		if value, err := s.getFloatValue(match.Value); err == nil {
+			values[match.Key] = value
+		} else {
+			return nil, s.keyError(match.Key, err)
+		}
+	}
+	return values, nil
+}
+
+// Query for bool values. The result maps each concrete key to its value.
+func (s *Settings) QueryBools(pattern string) (map[string]bool, error) {
+	matches, err := s.Query(pattern)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]bool, len(matches))
+	for _, match := range matches {
+		if value, err := getBoolValue(match.Value); err == nil {
+			values[match.Key] = value
+		} else {
+			return nil, s.keyError(match.Key, err)
+		}
+	}
+	return values, nil
+}
+
+// Query for duration values. The result maps each concrete key to its value.
+func (s *Settings) QueryDurations(pattern string) (map[string]time.Duration, error) {
+	matches, err := s.Query(pattern)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]time.Duration, len(matches))
+	for _, match := range matches {
+		if value, err := s.getDurationValue(match.Value); err == nil {
+			values[match.Key] = value
+		} else {
+			return nil, s.keyError(match.Key, err)
+		}
+	}
+	return values, nil
+}
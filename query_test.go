@@ -0,0 +1,120 @@
+package settings
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func getQuerySettings() *Settings {
+	settings, _ := Parse([]byte(`servers:
+  web:
+    port: 80
+    timeout: 5s
+  api:
+    port: 8080
+    timeout: 10s
+    upstream:
+      timeout: 30s
+backends:
+- name: primary
+  port: 9000
+- name: secondary
+  port: 9001
+timeout: 1m
+"*": star`))
+	return settings
+}
+
+func TestQuery(t *testing.T) {
+	settings := getQuerySettings()
+
+	type test struct {
+		pattern string
+		keys    []string
+	}
+
+	tests := []test{
+		{"servers.*.port", []string{"servers.api.port", "servers.web.port"}},
+		{"backends.*.port", []string{"backends.0.port", "backends.1.port"}},
+		{"**.timeout", []string{"timeout", "servers.api.timeout", "servers.api.upstream.timeout", "servers.web.timeout"}},
+		{"servers.**.timeout", []string{"servers.api.timeout", "servers.api.upstream.timeout", "servers.web.timeout"}},
+		{"**.**.timeout", []string{"timeout", "servers.api.timeout", "servers.api.upstream.timeout", "servers.web.timeout"}},
+		{"*.web", []string{"servers.web"}},
+		{"backends[?port!=9000].name", []string{"backends.1.name"}},
+		{"backends[port=9001]", []string{"backends.1"}},
+		{"backends.-1.name", []string{"backends.1.name"}},
+		{`"*"`, []string{`"*"`}},
+		{"timeout", []string{"timeout"}},
+		{"servers.*.missing", []string{}},
+		{"nope.*", []string{}},
+	}
+
+	for _, test := range tests {
+		matches, err := settings.Query(test.pattern)
+		if err != nil {
+			t.Errorf("error querying %s: %s", test.pattern, err)
+			continue
+		}
+		keys := make([]string, len(matches))
+		for n, match := range matches {
+			keys[n] = match.Key
+			if value, err := settings.Raw(match.Key); err != nil || !reflect.DeepEqual(value, match.Value) {
+				t.Errorf("%s: %v != %v (%v)", match.Key, match.Value, value, err)
+			}
+		}
+		if !reflect.DeepEqual(keys, test.keys) {
+			t.Errorf("%s: %v != %v", test.pattern, keys, test.keys)
+		}
+	}
+
+	if _, err := settings.Query(`"*`); !errors.Is(err, SyntaxError) {
+		t.Errorf("pattern %s is valid", `"*`)
+	}
+}
+
+func TestQueryTyped(t *testing.T) {
+	settings := getQuerySettings()
+
+	wantInts := map[string]int{"servers.api.port": 8080, "servers.web.port": 80}
+	if value, err := settings.QueryInts("servers.*.port"); err == nil {
+		if !reflect.DeepEqual(wantInts, value) {
+			t.Errorf("%v != %v", wantInts, value)
+		}
+	} else {
+		t.Error(err)
+	}
+
+	wantStrings := map[string]string{"backends.0.name": "primary", "backends.1.name": "secondary"}
+	if value, err := settings.QueryStrings("backends.*.name"); err == nil {
+		if !reflect.DeepEqual(wantStrings, value) {
+			t.Errorf("%v != %v", wantStrings, value)
+		}
+	} else {
+		t.Error(err)
+	}
+
+	wantDurations := map[string]time.Duration{
+		"servers.api.timeout":          10 * time.Second,
+		"servers.api.upstream.timeout": 30 * time.Second,
+		"servers.web.timeout":          5 * time.Second,
+	}
+	if value, err := settings.QueryDurations("servers.**.timeout"); err == nil {
+		if !reflect.DeepEqual(wantDurations, value) {
+			t.Errorf("%v != %v", wantDurations, value)
+		}
+	} else {
+		t.Error(err)
+	}
+
+	if value, err := settings.QueryFloats("backends.*.port"); err != nil || len(value) != 2 {
+		t.Errorf("invalid floats: %v (%v)", value, err)
+	}
+	if _, err := settings.QueryBools("servers.*.timeout"); err == nil {
+		t.Error("timeouts converted to bools")
+	}
+	if _, err := settings.QueryInts("servers.*"); err == nil {
+		t.Error("servers converted to ints")
+	}
+}
@@ -0,0 +1,130 @@
+package settings
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Range is an interval of integers. Bounds are inclusive unless the
+// corresponding Exclude field is set.
+type Range struct {
+	Min        int
+	Max        int
+	ExcludeMin bool
+	ExcludeMax bool
+}
+
+// Contains returns true if `n` lies within the range.
+func (r Range) Contains(n int) bool {
+	if n < r.Min || (r.ExcludeMin && n == r.Min) {
+		return false
+	}
+	if n > r.Max || (r.ExcludeMax && n == r.Max) {
+		return false
+	}
+	return true
+}
+
+// String formats the range using interval notation, e.g. `[1, 10)`.
+func (r Range) String() string {
+	lower, upper := "[", "]"
+	if r.ExcludeMin {
+		lower = "("
+	}
+	if r.ExcludeMax {
+		upper = ")"
+	}
+	return fmt.Sprintf("%s%d, %d%s", lower, r.Min, r.Max, upper)
+}
+
+// Ensure that the minimum is not larger than the maximum.
+func (r Range) validate() (Range, error) {
+	if r.Min > r.Max {
+		return Range{}, fmt.Errorf("range %s has min > max", r)
+	}
+	return r, nil
+}
+
+// Parse a range. The following formats are accepted:
+//
+//   - A single integer, e.g. `8080`, which is both the minimum and maximum.
+//   - Two integers separated by a dash, e.g. `8000-8100`. Both bounds are
+//     inclusive.
+//   - Interval notation, e.g. `[1, 10)`, where a parenthesis marks an
+//     exclusive bound.
+func ParseRange(s string) (Range, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Range{}, fmt.Errorf("invalid range %q", s)
+	}
+
+	if n, err := strconv.Atoi(s); err == nil {
+		return Range{Min: n, Max: n}, nil
+	}
+
+	var r Range
+	var minStr, maxStr string
+	if strings.ContainsAny(s[:1], "[(") {
+		last := s[len(s)-1:]
+		if !strings.ContainsAny(last, "])") {
+			return Range{}, fmt.Errorf("invalid range %q", s)
+		}
+		r.ExcludeMin = s[0] == '('
+		r.ExcludeMax = last == ")"
+		parts := strings.Split(s[1:len(s)-1], ",")
+		if len(parts) != 2 {
+			return Range{}, fmt.Errorf("invalid range %q", s)
+		}
+		minStr, maxStr = parts[0], parts[1]
+	} else {
+		// skip the first character to allow for a negative minimum
+		n := strings.IndexRune(s[1:], '-') + 1
+		if n == 0 {
+			return Range{}, fmt.Errorf("invalid range %q", s)
+		}
+		minStr, maxStr = s[:n], s[n+1:]
+	}
+
+	var err error
+	if r.Min, err = strconv.Atoi(strings.TrimSpace(minStr)); err != nil {
+		return Range{}, fmt.Errorf("invalid range %q", s)
+	}
+	if r.Max, err = strconv.Atoi(strings.TrimSpace(maxStr)); err != nil {
+		return Range{}, fmt.Errorf("invalid range %q", s)
+	}
+	return r.validate()
+}
+
+// Convert a settings value to a range. Accepts integers, strings in the formats
+// understood by ParseRange, and objects with `min` and `max` keys and optional
+// `exclude-min` and `exclude-max` flags.
+func (s *Settings) getRangeValue(key string, value interface{}) (Range, error) {
+	switch value.(type) {
+	case int:
+		n := value.(int)
+		return Range{Min: n, Max: n}, nil
+	case string:
+		return ParseRange(value.(string))
+	case map[interface{}]interface{}:
+		object := s.child(key, value.(map[interface{}]interface{}))
+		var r Range
+		var err error
+		if r.Min, err = object.Int("min"); err != nil {
+			return Range{}, err
+		}
+		if r.Max, err = object.Int("max"); err != nil {
+			return Range{}, err
+		}
+		if r.ExcludeMin, err = object.Bool("exclude-min"); err != nil && !errors.Is(err, KeyError) {
+			return Range{}, err
+		}
+		if r.ExcludeMax, err = object.Bool("exclude-max"); err != nil && !errors.Is(err, KeyError) {
+			return Range{}, err
+		}
+		return r.validate()
+	default:
+		return Range{}, typeError("range", value)
+	}
+}
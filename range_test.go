@@ -0,0 +1,69 @@
+package settings
+
+import (
+	"testing"
+)
+
+func TestParseRange(t *testing.T) {
+	type test struct {
+		str string
+		r   Range
+	}
+
+	tests := []test{
+		{"8080", Range{Min: 8080, Max: 8080}},
+		{"8000-8100", Range{Min: 8000, Max: 8100}},
+		{" 8000 - 8100 ", Range{Min: 8000, Max: 8100}},
+		{"-10--5", Range{Min: -10, Max: -5}},
+		{"-10-5", Range{Min: -10, Max: 5}},
+		{"[1, 10]", Range{Min: 1, Max: 10}},
+		{"[1,10)", Range{Min: 1, Max: 10, ExcludeMax: true}},
+		{"(1, 10]", Range{Min: 1, Max: 10, ExcludeMin: true}},
+	}
+
+	for _, test := range tests {
+		if r, err := ParseRange(test.str); err != nil {
+			t.Errorf("error parsing %s: %s", test.str, err)
+		} else if r != test.r {
+			t.Errorf("range is invalid: %v != %v", r, test.r)
+		}
+	}
+
+	errors := []string{"", "-", "a-b", "10-1", "[1, 10", "[1]", "[1, 2, 3]", "1-2-3"}
+	for _, str := range errors {
+		if _, err := ParseRange(str); err == nil {
+			t.Errorf("no error parsing %s", str)
+		}
+	}
+}
+
+func TestRangeContains(t *testing.T) {
+	type test struct {
+		r    Range
+		n    int
+		want bool
+	}
+
+	tests := []test{
+		{Range{Min: 1, Max: 10}, 1, true},
+		{Range{Min: 1, Max: 10}, 10, true},
+		{Range{Min: 1, Max: 10}, 0, false},
+		{Range{Min: 1, Max: 10}, 11, false},
+		{Range{Min: 1, Max: 10, ExcludeMin: true}, 1, false},
+		{Range{Min: 1, Max: 10, ExcludeMax: true}, 10, false},
+		{Range{Min: 1, Max: 10, ExcludeMax: true}, 9, true},
+	}
+
+	for _, test := range tests {
+		if have := test.r.Contains(test.n); have != test.want {
+			t.Errorf("%v.Contains(%d) != %t", test.r, test.n, test.want)
+		}
+	}
+}
+
+func TestRangeString(t *testing.T) {
+	r := Range{Min: 1, Max: 10, ExcludeMax: true}
+	if have := r.String(); have != "[1, 10)" {
+		t.Errorf("%s != %s", "[1, 10)", have)
+	}
+}
@@ -0,0 +1,411 @@
+package settings
+
+import (
+	"crypto"
+	"crypto/x509"
+	"errors"
+	"os"
+	"regexp"
+	"time"
+)
+
+// Reader wraps a settings object to read many values without checking an
+// error after each one. Its getters return the zero value when an error
+// occurs and record the error. Call Err once all values have been read to get
+// every error at once.
+type Reader struct {
+	Settings *Settings
+
+	// errors shared with the readers of child objects
+	errs *[]error
+	// errors are not recorded for an object which could not be read
+	quiet bool
+}
+
+// Reader returns a reader for the settings.
+func (s *Settings) Reader() *Reader {
+	return &Reader{Settings: s, errs: &[]error{}}
+}
+
+// Err returns the errors recorded by the reader and the readers of its child
+// objects joined into one error, or nil if no error occurred. The errors may
+// be tested with errors.Is and errors.As.
+func (r *Reader) Err() error {
+	return errors.Join(*r.errs...)
+}
+
+// Record an error if one occurred. Return true if it did.
+func (r *Reader) fail(err error) bool {
+	if err == nil {
+		return false
+	}
+	if !r.quiet {
+		*r.errs = append(*r.errs, err)
+	}
+	return true
+}
+
+// Return a reader for a child object which shares the errors of this one.
+func (r *Reader) child(s *Settings) *Reader {
+	return &Reader{Settings: s, errs: r.errs, quiet: r.quiet}
+}
+
+// Get a settings object as a reader. Errors reading from it are recorded with
+// those of this reader. If the object cannot be read an empty reader which
+// records no further errors is returned.
+func (r *Reader) Object(key string) *Reader {
+	if value, err := r.Settings.Object(key); !r.fail(err) {
+		return r.child(value)
+	}
+	return &Reader{Settings: r.Settings.child(key, map[interface{}]interface{}{}), errs: r.errs, quiet: true}
+}
+
+// Get an array of settings objects as readers.
+func (r *Reader) ObjectArray(key string) []*Reader {
+	values, err := r.Settings.ObjectArray(key)
+	if r.fail(err) {
+		return nil
+	}
+	readers := make([]*Reader, len(values))
+	for n, value := range values {
+		readers[n] = r.child(value)
+	}
+	return readers
+}
+
+// Get a map of settings objects as readers.
+func (r *Reader) ObjectMap(key string) map[string]*Reader {
+	values, err := r.Settings.ObjectMap(key)
+	if r.fail(err) {
+		return nil
+	}
+	readers := make(map[string]*Reader, len(values))
+	for name, value := range values {
+		readers[name] = r.child(value)
+	}
+	return readers
+}
+
+// Get a value. Return nil if an error occurs.
+func (r *Reader) Raw(key string) interface{} {
+	if value, err := r.Settings.Raw(key); !r.fail(err) {
+		return value
+	}
+	return nil
+}
+
+// Get a string value. Return an empty string if an error occurs.
+func (r *Reader) String(key string) string {
+	if value, err := r.Settings.String(key); !r.fail(err) {
+		return value
+	}
+	return ""
+}
+
+// Get an array of string values. Return nil if an error occurs.
+func (r *Reader) StringArray(key string) []string {
+	if value, err := r.Settings.StringArray(key); !r.fail(err) {
+		return value
+	}
+	return nil
+}
+
+// Get a map of string values. Return nil if an error occurs.
+func (r *Reader) StringMap(key string) map[string]string {
+	if value, err := r.Settings.StringMap(key); !r.fail(err) {
+		return value
+	}
+	return nil
+}
+
+// Get an integer value. Return zero if an error occurs.
+func (r *Reader) Int(key string) int {
+	if value, err := r.Settings.Int(key); !r.fail(err) {
+		return value
+	}
+	return 0
+}
+
+// Get an array of integer values. Return nil if an error occurs.
+func (r *Reader) IntArray(key string) []int {
+	if value, err := r.Settings.IntArray(key); !r.fail(err) {
+		return value
+	}
+	return nil
+}
+
+// Get a map of integer values. Return nil if an error occurs.
+func (r *Reader) IntMap(key string) map[string]int {
+	if value, err := r.Settings.IntMap(key); !r.fail(err) {
+		return value
+	}
+	return nil
+}
+
+// Get a float value. Return zero if an error occurs.
+func (r *Reader) Float(key string) float64 {
+	if value, err := r.Settings.Float(key); !r.fail(err) {
+		return value
+	}
+	return 0
+}
+
+// Get an array of float values. Return nil if an error occurs.
+func (r *Reader) FloatArray(key string) []float64 {
+	if value, err := r.Settings.FloatArray(key); !r.fail(err) {
+		return value
+	}
+	return nil
+}
+
+// Get a map of float values. Return nil if an error occurs.
+func (r *Reader) FloatMap(key string) map[string]float64 {
+	if value, err := r.Settings.FloatMap(key); !r.fail(err) {
+		return value
+	}
+	return nil
+}
+
+// Get a boolean value. Return false if an error occurs.
+func (r *Reader) Bool(key string) bool {
+	if value, err := r.Settings.Bool(key); !r.fail(err) {
+		return value
+	}
+	return false
+}
+
+// Get an array of boolean values. Return nil if an error occurs.
+func (r *Reader) BoolArray(key string) []bool {
+	if value, err := r.Settings.BoolArray(key); !r.fail(err) {
+		return value
+	}
+	return nil
+}
+
+// Get a map of boolean values. Return nil if an error occurs.
+func (r *Reader) BoolMap(key string) map[string]bool {
+	if value, err := r.Settings.BoolMap(key); !r.fail(err) {
+		return value
+	}
+	return nil
+}
+
+// Get a duration value. Return zero if an error occurs.
+func (r *Reader) Duration(key string) time.Duration {
+	if value, err := r.Settings.Duration(key); !r.fail(err) {
+		return value
+	}
+	return 0
+}
+
+// Get an array of duration values. Return nil if an error occurs.
+func (r *Reader) DurationArray(key string) []time.Duration {
+	if value, err := r.Settings.DurationArray(key); !r.fail(err) {
+		return value
+	}
+	return nil
+}
+
+// Get a map of duration values. Return nil if an error occurs.
+func (r *Reader) DurationMap(key string) map[string]time.Duration {
+	if value, err := r.Settings.DurationMap(key); !r.fail(err) {
+		return value
+	}
+	return nil
+}
+
+// Get a size value. Return zero if an error occurs.
+func (r *Reader) Size(key string) int64 {
+	if value, err := r.Settings.Size(key); !r.fail(err) {
+		return value
+	}
+	return 0
+}
+
+// Get an array of size values. Return nil if an error occurs.
+func (r *Reader) SizeArray(key string) []int64 {
+	if value, err := r.Settings.SizeArray(key); !r.fail(err) {
+		return value
+	}
+	return nil
+}
+
+// Get a map of size values. Return nil if an error occurs.
+func (r *Reader) SizeMap(key string) map[string]int64 {
+	if value, err := r.Settings.SizeMap(key); !r.fail(err) {
+		return value
+	}
+	return nil
+}
+
+// Get a regular expression value. Return nil if an error occurs.
+func (r *Reader) Regexp(key string) *regexp.Regexp {
+	if value, err := r.Settings.Regexp(key); !r.fail(err) {
+		return value
+	}
+	return nil
+}
+
+// Get an array of regular expression values. Return nil if an error
+// occurs.
+func (r *Reader) RegexpArray(key string) []*regexp.Regexp {
+	if value, err := r.Settings.RegexpArray(key); !r.fail(err) {
+		return value
+	}
+	return nil
+}
+
+// Get a map of regular expression values. Return nil if an error
+// occurs.
+func (r *Reader) RegexpMap(key string) map[string]*regexp.Regexp {
+	if value, err := r.Settings.RegexpMap(key); !r.fail(err) {
+		return value
+	}
+	return nil
+}
+
+// Get a string value which must match one of the choices. Return an empty
+// string if an error occurs.
+func (r *Reader) Choice(key string, choices Choices) string {
+	if value, err := r.Settings.Choice(key, choices); !r.fail(err) {
+		return value
+	}
+	return ""
+}
+
+// Get a string value which must be one of the `allowed` values. Return an
+// empty string if an error occurs.
+func (r *Reader) Enum(key string, allowed ...string) string {
+	if value, err := r.Settings.Enum(key, allowed...); !r.fail(err) {
+		return value
+	}
+	return ""
+}
+
+// Get a path value. Return an empty string if an error occurs.
+func (r *Reader) Path(key string, checks ...PathCheck) string {
+	if value, err := r.Settings.Path(key, checks...); !r.fail(err) {
+		return value
+	}
+	return ""
+}
+
+// Get an array of path values. Return nil if an error occurs.
+func (r *Reader) PathArray(key string, checks ...PathCheck) []string {
+	if value, err := r.Settings.PathArray(key, checks...); !r.fail(err) {
+		return value
+	}
+	return nil
+}
+
+// Get a file mode value. Return zero if an error occurs.
+func (r *Reader) FileMode(key string) os.FileMode {
+	if value, err := r.Settings.FileMode(key); !r.fail(err) {
+		return value
+	}
+	return 0
+}
+
+// Get a user ID value. Return zero if an error occurs.
+func (r *Reader) UserID(key string) int {
+	if value, err := r.Settings.UserID(key); !r.fail(err) {
+		return value
+	}
+	return 0
+}
+
+// Get a group ID value. Return zero if an error occurs.
+func (r *Reader) GroupID(key string) int {
+	if value, err := r.Settings.GroupID(key); !r.fail(err) {
+		return value
+	}
+	return 0
+}
+
+// Get a range value. Return the zero value if an error occurs.
+func (r *Reader) Range(key string) Range {
+	if value, err := r.Settings.Range(key); !r.fail(err) {
+		return value
+	}
+	return Range{}
+}
+
+// Get a port range value. Return the zero value if an error occurs.
+func (r *Reader) PortRange(key string) Range {
+	if value, err := r.Settings.PortRange(key); !r.fail(err) {
+		return value
+	}
+	return Range{}
+}
+
+// Get a ratio value. Return zero if an error occurs.
+func (r *Reader) Ratio(key string) float64 {
+	if value, err := r.Settings.Ratio(key); !r.fail(err) {
+		return value
+	}
+	return 0
+}
+
+// Get an array of ratio values. Return nil if an error occurs.
+func (r *Reader) RatioArray(key string) []float64 {
+	if value, err := r.Settings.RatioArray(key); !r.fail(err) {
+		return value
+	}
+	return nil
+}
+
+// Get a map of ratio values. Return nil if an error occurs.
+func (r *Reader) RatioMap(key string) map[string]float64 {
+	if value, err := r.Settings.RatioMap(key); !r.fail(err) {
+		return value
+	}
+	return nil
+}
+
+// Get binary data. Return nil if an error occurs.
+func (r *Reader) Bytes(key string) []byte {
+	if value, err := r.Settings.Bytes(key); !r.fail(err) {
+		return value
+	}
+	return nil
+}
+
+// Get an array of certificates. Return nil if an error occurs.
+func (r *Reader) Certificates(key string) []*x509.Certificate {
+	if value, err := r.Settings.Certificates(key); !r.fail(err) {
+		return value
+	}
+	return nil
+}
+
+// Get a private key. Return nil if an error occurs.
+func (r *Reader) PrivateKey(key string) crypto.Signer {
+	if value, err := r.Settings.PrivateKey(key); !r.fail(err) {
+		return value
+	}
+	return nil
+}
+
+// Get a time of day value. Return the zero value if an error occurs.
+func (r *Reader) TimeOfDay(key string) TimeOfDay {
+	if value, err := r.Settings.TimeOfDay(key); !r.fail(err) {
+		return value
+	}
+	return TimeOfDay{}
+}
+
+// Get a window value. Return the zero value if an error occurs.
+func (r *Reader) Window(key string) Window {
+	if value, err := r.Settings.Window(key); !r.fail(err) {
+		return value
+	}
+	return Window{}
+}
+
+// Get a schedule value. Return the zero value if an error occurs.
+func (r *Reader) Schedule(key string) Schedule {
+	if value, err := r.Settings.Schedule(key); !r.fail(err) {
+		return value
+	}
+	return Schedule{}
+}
@@ -0,0 +1,96 @@
+package settings
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReader(t *testing.T) {
+	settings := getSettings()
+	r := settings.Reader()
+
+	if value := r.String("key"); value != "value" {
+		t.Errorf("%v != %v", "value", value)
+	}
+	if value := r.IntArray("integer-array"); !reflect.DeepEqual(value, []int{1, 2}) {
+		t.Errorf("%v != %v", []int{1, 2}, value)
+	}
+	if err := r.Err(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// errors are accumulated and zero values returned
+	if value := r.Int("missing"); value != 0 {
+		t.Errorf("%v != %v", 0, value)
+	}
+	if value := r.Int("mapping.a"); value != 0 {
+		t.Errorf("%v != %v", 0, value)
+	}
+	if value := r.StringArray("mixed-array"); value != nil {
+		t.Errorf("%v != %v", nil, value)
+	}
+	if value := r.Enum("key", "a", "b"); value != "" {
+		t.Errorf("%v != %v", "", value)
+	}
+
+	err := r.Err()
+	if err == nil {
+		t.Fatal("errors not reported")
+	}
+	for _, key := range []string{"missing", "mapping.a", "mixed-array.1", "key"} {
+		if !strings.Contains(err.Error(), key+": ") {
+			t.Errorf("error does not name key %s: %v", key, err)
+		}
+	}
+	if !errors.Is(err, KeyError) || !errors.Is(err, TypeError) {
+		t.Errorf("error does not wrap KeyError and TypeError: %v", err)
+	}
+	var enumErr *EnumError
+	if !errors.As(err, &enumErr) || enumErr.Value != "value" {
+		t.Errorf("error does not wrap the EnumError: %v", err)
+	}
+	if n := len(strings.Split(err.Error(), "\n")); n != 4 {
+		t.Errorf("%d errors reported instead of 4", n)
+	}
+}
+
+func TestReaderObject(t *testing.T) {
+	settings := getSettings()
+	r := settings.Reader()
+
+	objects := r.ObjectArray("settings-array")
+	if len(objects) != 2 {
+		t.Fatalf("%d objects read instead of 2", len(objects))
+	}
+	if value := objects[1].String("name"); value != "two" {
+		t.Errorf("%v != %v", "two", value)
+	}
+	objects[0].Int("value")
+
+	mapping := r.ObjectMap("settings-map")
+	mapping["one"].Bool("missing")
+
+	// a missing object reports its own error only
+	missing := r.Object("missing")
+	if value := missing.String("name"); value != "" {
+		t.Errorf("%v != %v", "", value)
+	}
+	missing.Object("child").Int("port")
+
+	err := r.Err()
+	if err == nil {
+		t.Fatal("errors not reported")
+	}
+	lines := strings.Split(err.Error(), "\n")
+	want := []string{"settings-array.0.value: ", "settings-map.one.missing: ", "missing: "}
+	if len(lines) != len(want) {
+		t.Fatalf("%d errors reported instead of %d: %v", len(lines), len(want), err)
+	}
+	for n, prefix := range want {
+		if !strings.Contains(lines[n], prefix) {
+			t.Errorf("error %q does not name %s", lines[n], prefix)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+//go:build unix
+
+package settings
+
+import (
+	"syscall"
+)
+
+// Get a signal value. Return zero if an error occurs.
+func (r *Reader) Signal(key string) syscall.Signal {
+	if value, err := r.Settings.Signal(key); !r.fail(err) {
+		return value
+	}
+	return 0
+}
@@ -0,0 +1,261 @@
+package settings
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeOfDay is a wall clock time without a date.
+type TimeOfDay struct {
+	Hour   int
+	Minute int
+	Second int
+}
+
+// Parse a time of day in 24 hour `hh:mm` or `hh:mm:ss` format. The time `24:00`
+// is accepted to denote the end of a day.
+func ParseTimeOfDay(s string) (TimeOfDay, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return TimeOfDay{}, fmt.Errorf("invalid time of day %q", s)
+	}
+
+	values := make([]int, 3)
+	for n, part := range parts {
+		value, err := strconv.Atoi(part)
+		if err != nil || len(part) > 2 || value < 0 {
+			return TimeOfDay{}, fmt.Errorf("invalid time of day %q", s)
+		}
+		values[n] = value
+	}
+
+	t := TimeOfDay{Hour: values[0], Minute: values[1], Second: values[2]}
+	if t.Minute > 59 || t.Second > 59 || t.Hour > 24 || (t.Hour == 24 && t.offset() != 24*time.Hour) {
+		return TimeOfDay{}, fmt.Errorf("time of day %q out of range", s)
+	}
+	return t, nil
+}
+
+// String formats the time as `hh:mm` or `hh:mm:ss` if seconds are set.
+func (t TimeOfDay) String() string {
+	if t.Second != 0 {
+		return fmt.Sprintf("%02d:%02d:%02d", t.Hour, t.Minute, t.Second)
+	}
+	return fmt.Sprintf("%02d:%02d", t.Hour, t.Minute)
+}
+
+// Return the time elapsed since midnight.
+func (t TimeOfDay) offset() time.Duration {
+	return time.Duration(t.Hour)*time.Hour + time.Duration(t.Minute)*time.Minute +
+		time.Duration(t.Second)*time.Second
+}
+
+// On returns the time of day on the date of `date` in the location of `date`.
+func (t TimeOfDay) On(date time.Time) time.Time {
+	year, month, day := date.Date()
+	return time.Date(year, month, day, t.Hour, t.Minute, t.Second, 0, date.Location())
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Parse a weekday name. Names are case insensitive and may be abbreviated to
+// three letters.
+func ParseWeekday(s string) (time.Weekday, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	if len(name) >= 3 {
+		if day, ok := weekdays[name[:3]]; ok && strings.HasPrefix(strings.ToLower(day.String()), name) {
+			return day, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid weekday %q", s)
+}
+
+// Parse a weekday or a range of weekdays such as `mon-fri`. Ranges may wrap
+// around the end of the week.
+func parseWeekdays(s string) ([]time.Weekday, error) {
+	parts := strings.Split(s, "-")
+	if len(parts) > 2 {
+		return nil, fmt.Errorf("invalid weekday range %q", s)
+	}
+	first, err := ParseWeekday(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) == 1 {
+		return []time.Weekday{first}, nil
+	}
+	last, err := ParseWeekday(parts[1])
+	if err != nil {
+		return nil, err
+	}
+
+	days := []time.Weekday{first}
+	for day := first; day != last; {
+		day = (day + 1) % 7
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+// Window is a recurring window of time on some days of the week. A window
+// which ends at or before the time it starts runs past midnight into the
+// following day. Days refer to the day on which the window starts.
+type Window struct {
+	// The days on which the window starts. Every day if empty.
+	Days []time.Weekday
+	// The start of the window.
+	From TimeOfDay
+	// The end of the window.
+	To TimeOfDay
+	// The time zone of the window. Local time if nil.
+	Location *time.Location
+}
+
+// Return true if the window starts on the given day.
+func (w Window) startsOn(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, d := range w.Days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// Return the start and end of the occurrence of the window which starts on the
+// date of `date`.
+func (w Window) occurrence(date time.Time) (start, end time.Time) {
+	start = w.From.On(date)
+	end = w.To.On(date)
+	if !end.After(start) {
+		end = w.To.On(date.AddDate(0, 0, 1))
+	}
+	return
+}
+
+// Return `now` in the location of the window.
+func (w Window) in(now time.Time) time.Time {
+	if w.Location == nil {
+		return now.Local()
+	}
+	return now.In(w.Location)
+}
+
+// Active returns true if `now` falls within an occurrence of the window.
+func (w Window) Active(now time.Time) bool {
+	now = w.in(now)
+	for _, offset := range []int{0, -1} {
+		date := now.AddDate(0, 0, offset)
+		if !w.startsOn(date.Weekday()) {
+			continue
+		}
+		if start, end := w.occurrence(date); !now.Before(start) && now.Before(end) {
+			return true
+		}
+	}
+	return false
+}
+
+// Next returns the start of the first occurrence of the window after `now`.
+// The zero time is returned if the window never occurs.
+func (w Window) Next(now time.Time) time.Time {
+	local := w.in(now)
+	for offset := 0; offset <= 7; offset++ {
+		date := local.AddDate(0, 0, offset)
+		if !w.startsOn(date.Weekday()) {
+			continue
+		}
+		if start, _ := w.occurrence(date); start.After(now) {
+			return start
+		}
+	}
+	return time.Time{}
+}
+
+// Schedule is a set of windows.
+type Schedule []Window
+
+// Active returns true if any window in the schedule is active.
+func (s Schedule) Active(now time.Time) bool {
+	for _, w := range s {
+		if w.Active(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// Next returns the earliest start of any window in the schedule after `now`.
+// The zero time is returned if no window occurs.
+func (s Schedule) Next(now time.Time) time.Time {
+	var next time.Time
+	for _, w := range s {
+		if start := w.Next(now); !start.IsZero() && (next.IsZero() || start.Before(next)) {
+			next = start
+		}
+	}
+	return next
+}
+
+// Convert a settings object to a window. The object has `from` and `to` times
+// of day and optional `days` and `tz` keys. Days may be a single day, a range
+// such as `mon-fri`, or an array of either.
+func (s *Settings) getWindowValue(key string, value interface{}) (Window, error) {
+	mapping, ok := value.(map[interface{}]interface{})
+	if !ok {
+		return Window{}, typeError("object", value)
+	}
+	object := s.child(key, mapping)
+
+	var w Window
+	for _, part := range []struct {
+		name string
+		time *TimeOfDay
+	}{{"from", &w.From}, {"to", &w.To}} {
+		if str, err := object.String(part.name); err == nil {
+			if *part.time, err = ParseTimeOfDay(str); err != nil {
+				return Window{}, s.keyError(s.joinKey(key, part.name), err)
+			}
+		} else {
+			return Window{}, err
+		}
+	}
+
+	var days []string
+	if day, err := object.String("days"); err == nil {
+		days = []string{day}
+	} else if errors.Is(err, TypeError) {
+		if days, err = object.StringArray("days"); err != nil {
+			return Window{}, err
+		}
+	}
+	for _, day := range days {
+		if parsed, err := parseWeekdays(day); err == nil {
+			w.Days = append(w.Days, parsed...)
+		} else {
+			return Window{}, s.keyError(s.joinKey(key, "days"), err)
+		}
+	}
+
+	if tz, err := object.String("tz"); err == nil {
+		if w.Location, err = time.LoadLocation(tz); err != nil {
+			return Window{}, s.keyError(s.joinKey(key, "tz"), err)
+		}
+	} else if !errors.Is(err, KeyError) {
+		return Window{}, err
+	}
+	return w, nil
+}
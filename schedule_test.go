@@ -0,0 +1,153 @@
+package settings
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseTimeOfDay(t *testing.T) {
+	type test struct {
+		str string
+		t   TimeOfDay
+	}
+
+	tests := []test{
+		{"02:30", TimeOfDay{Hour: 2, Minute: 30}},
+		{"2:30", TimeOfDay{Hour: 2, Minute: 30}},
+		{" 23:59:59 ", TimeOfDay{Hour: 23, Minute: 59, Second: 59}},
+		{"00:00", TimeOfDay{}},
+		{"24:00", TimeOfDay{Hour: 24}},
+	}
+
+	for _, test := range tests {
+		if tod, err := ParseTimeOfDay(test.str); err != nil {
+			t.Errorf("error parsing %s: %s", test.str, err)
+		} else if tod != test.t {
+			t.Errorf("time is invalid: %v != %v", tod, test.t)
+		}
+	}
+
+	errors := []string{"", "2", "2:", "25:00", "24:01", "12:60", "12:00:60", "-1:00", "1:2:3:4", "012:00"}
+	for _, str := range errors {
+		if _, err := ParseTimeOfDay(str); err == nil {
+			t.Errorf("no error parsing %s", str)
+		}
+	}
+}
+
+func TestTimeOfDayString(t *testing.T) {
+	if have := (TimeOfDay{Hour: 2, Minute: 30}).String(); have != "02:30" {
+		t.Errorf("%s != %s", "02:30", have)
+	}
+	if have := (TimeOfDay{Hour: 2, Minute: 30, Second: 5}).String(); have != "02:30:05" {
+		t.Errorf("%s != %s", "02:30:05", have)
+	}
+}
+
+func TestParseWeekdays(t *testing.T) {
+	type test struct {
+		str  string
+		days []time.Weekday
+	}
+
+	tests := []test{
+		{"sat", []time.Weekday{time.Saturday}},
+		{"Saturday", []time.Weekday{time.Saturday}},
+		{"MON", []time.Weekday{time.Monday}},
+		{"mon-wed", []time.Weekday{time.Monday, time.Tuesday, time.Wednesday}},
+		{"fri-mon", []time.Weekday{time.Friday, time.Saturday, time.Sunday, time.Monday}},
+	}
+
+	for _, test := range tests {
+		if days, err := parseWeekdays(test.str); err != nil {
+			t.Errorf("error parsing %s: %s", test.str, err)
+		} else if !reflect.DeepEqual(days, test.days) {
+			t.Errorf("days are invalid: %v != %v", days, test.days)
+		}
+	}
+
+	errors := []string{"", "sa", "satx", "caturday", "mon-", "mon-tue-wed"}
+	for _, str := range errors {
+		if _, err := parseWeekdays(str); err == nil {
+			t.Errorf("no error parsing %s", str)
+		}
+	}
+}
+
+func TestWindow(t *testing.T) {
+	date := func(day, hour, minute int) time.Time {
+		// 2024-06-01 is a Saturday
+		return time.Date(2024, time.June, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	weekend := Window{
+		Days:     []time.Weekday{time.Saturday, time.Sunday},
+		From:     TimeOfDay{Hour: 1},
+		To:       TimeOfDay{Hour: 5},
+		Location: time.UTC,
+	}
+	overnight := Window{
+		Days:     []time.Weekday{time.Friday},
+		From:     TimeOfDay{Hour: 22},
+		To:       TimeOfDay{Hour: 2},
+		Location: time.UTC,
+	}
+
+	type test struct {
+		w      Window
+		now    time.Time
+		active bool
+		next   time.Time
+	}
+
+	tests := []test{
+		{weekend, date(1, 0, 59), false, date(1, 1, 0)},
+		{weekend, date(1, 1, 0), true, date(2, 1, 0)},
+		{weekend, date(1, 4, 59), true, date(2, 1, 0)},
+		{weekend, date(1, 5, 0), false, date(2, 1, 0)},
+		{weekend, date(2, 6, 0), false, date(8, 1, 0)},
+		{overnight, date(1, 1, 0), true, date(7, 22, 0)},
+		{overnight, date(1, 2, 0), false, date(7, 22, 0)},
+		{overnight, date(7, 23, 0), true, date(14, 22, 0)},
+		{overnight, date(6, 23, 0), false, date(7, 22, 0)},
+	}
+
+	for _, test := range tests {
+		if active := test.w.Active(test.now); active != test.active {
+			t.Errorf("%v: active %t != %t", test.now, active, test.active)
+		}
+		if next := test.w.Next(test.now); !next.Equal(test.next) {
+			t.Errorf("%v: next %v != %v", test.now, next, test.next)
+		}
+	}
+
+	// time zones
+	tz := time.FixedZone("UTC+2", 2*60*60)
+	shifted := weekend
+	shifted.Location = tz
+	if !shifted.Active(date(1, 0, 30)) {
+		t.Errorf("%v is not active in %v", date(1, 0, 30), tz)
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	now := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+	schedule := Schedule{
+		{From: TimeOfDay{Hour: 18}, To: TimeOfDay{Hour: 19}, Location: time.UTC},
+		{From: TimeOfDay{Hour: 14}, To: TimeOfDay{Hour: 15}, Location: time.UTC},
+	}
+
+	if schedule.Active(now) {
+		t.Errorf("schedule is active at %v", now)
+	}
+	if want, next := now.Add(2*time.Hour), schedule.Next(now); !next.Equal(want) {
+		t.Errorf("%v != %v", want, next)
+	}
+	if !schedule.Active(now.Add(6 * time.Hour)) {
+		t.Errorf("schedule is not active at %v", now.Add(6*time.Hour))
+	}
+	if next := (Schedule{}).Next(now); !next.IsZero() {
+		t.Errorf("empty schedule has next %v", next)
+	}
+}
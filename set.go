@@ -1,49 +1,111 @@
 package settings
 
 import (
+	"errors"
 	"reflect"
 	"strconv"
-	"strings"
 )
 
-// Get the value at the index of the provided map or array.
-func getElement(obj interface{}, index string) (interface{}, error) {
+// Get the value at the index of the provided map or array. A slice index
+// returns a sub-array which shares storage with the array.
+func (s *Settings) getElement(obj interface{}, index string) (interface{}, error) {
 	switch obj.(type) {
 	case map[interface{}]interface{}:
-		if item, ok := (obj.(map[interface{}]interface{}))[index]; ok {
-			return item, nil
-		} else {
+		if value, err := s.mapValue(obj.(map[interface{}]interface{}), index); err == nil {
+			return value, nil
+		} else if err == KeyError {
 			return nil, IndexError
+		} else {
+			return nil, err
 		}
 	case []interface{}:
-		if n, err := strconv.Atoi(index); err == nil {
-			return (obj.([]interface{}))[n], nil
+		array := obj.([]interface{})
+		if start, end, ok, err := arraySlice(index, len(array)); ok {
+			if err != nil {
+				return nil, err
+			}
+			return array[start:end:end], nil
+		}
+		if n, err := arrayIndex(index, len(array)); err == nil {
+			return array[n], nil
+		} else {
+			return nil, err
 		}
 	}
-	return nil, ObjectError
+	return nil, objectError(obj)
 }
 
-// Set the value at the index of the provided map or array.
-func setElement(obj interface{}, index string, value interface{}) error {
+// Set the value at the index of the provided map or array and return the
+// object. An existing map key keeps its type and spelling. Setting the index
+// one past the end of an array appends to it and setting a slice replaces
+// those elements with the elements of an array value. The returned object
+// replaces the original when an array changes length.
+func (s *Settings) setElement(obj interface{}, index string, value interface{}) (interface{}, error) {
 	if obj, ok := value.(*Settings); ok {
 		value = obj.Values
 	}
 
 	if mapping, ok := obj.(map[interface{}]interface{}); ok {
-		mapping[index] = value
+		if key, err := s.mapKey(mapping, index); err == nil {
+			mapping[key] = value
+		} else if err == KeyError {
+			mapping[index] = value
+		} else {
+			return nil, err
+		}
+		return mapping, nil
 	} else if array, ok := obj.([]interface{}); ok {
-		n, err := strconv.Atoi(index)
-		if err != nil {
-			return IndexError
+		if start, end, ok, err := arraySlice(index, len(array)); ok {
+			if err != nil {
+				return nil, err
+			}
+			items, ok := value.([]interface{})
+			if !ok {
+				return nil, TypeError
+			}
+			result := make([]interface{}, 0, len(array)-(end-start)+len(items))
+			result = append(result, array[:start]...)
+			result = append(result, items...)
+			return append(result, array[end:]...), nil
 		}
-		if n < 0 || n >= len(array) {
-			return RangeError
+		if n, err := strconv.Atoi(index); err == nil && n == len(array) {
+			return append(array, value), nil
+		}
+		n, err := arrayIndex(index, len(array))
+		if err != nil {
+			return nil, err
 		}
 		array[n] = value
-	} else {
-		return ObjectError
+		return array, nil
 	}
-	return nil
+	return nil, objectError(obj)
+}
+
+// Remove the value at the index of the provided map or array and return the
+// object. A missing map key is not an error. The returned object replaces the
+// original when an array changes length.
+func (s *Settings) deleteElement(obj interface{}, index string) (interface{}, error) {
+	if mapping, ok := obj.(map[interface{}]interface{}); ok {
+		if key, err := s.mapKey(mapping, index); err == nil {
+			delete(mapping, key)
+		} else if err != KeyError {
+			return nil, err
+		}
+		return mapping, nil
+	} else if array, ok := obj.([]interface{}); ok {
+		start, end, ok, err := arraySlice(index, len(array))
+		if !ok {
+			start, err = arrayIndex(index, len(array))
+			end = start + 1
+		}
+		if err != nil {
+			return nil, err
+		}
+		result := make([]interface{}, 0, len(array)-(end-start))
+		result = append(result, array[:start]...)
+		return append(result, array[end:]...), nil
+	}
+	return nil, objectError(obj)
 }
 
 // Recursively convert value to an interface storage type.
@@ -71,6 +133,9 @@ func getInterface(value interface{}) interface{} {
 		}
 		return mapping
 	case reflect.Slice:
+		if refValue.Type().Elem().Kind() == reflect.Uint8 {
+			return value
+		}
 		fallthrough
 	case reflect.Array:
 		array := make([]interface{}, refValue.Len())
@@ -84,61 +149,129 @@ func getInterface(value interface{}) interface{} {
 	}
 }
 
-// Create maps in `values` along the provided path and return the last created map.
-func createPath(values interface{}, path []string) (interface{}, error) {
-	for _, name := range path {
-		if next, err := getElement(values, name); err == nil {
-			values = next
-		} else if err == IndexError {
-			next = make(map[interface{}]interface{})
-			setElement(values, name, next)
-			values = next
-		} else {
-			return nil, err
-		}
+// Set the value at the path below `obj` creating any missing objects along the
+// way. Return the object, which replaces the original when an array changes
+// length.
+func (s *Settings) setPath(obj interface{}, path []segment, value interface{}) (interface{}, error) {
+	name := path[0].name
+	if len(path) == 1 {
+		result, err := s.setElement(obj, name, value)
+		return result, segmentError(name, err)
 	}
-	return values, nil
+	child, err := s.getElement(obj, name)
+	if err == IndexError || err == RangeError {
+		child = make(map[interface{}]interface{})
+	} else if err != nil {
+		return nil, segmentError(name, err)
+	}
+	if child, err = s.setPath(child, path[1:], value); err != nil {
+		return nil, err
+	}
+	result, err := s.setElement(obj, name, child)
+	return result, segmentError(name, err)
 }
 
-// Get the parent object for the provided path creating any missing elements as necessary.
-func getParent(value interface{}, path []string) (parent interface{}, err error) {
+// Delete the value at the path below `obj`. A missing value is not an error.
+// Return the object, which replaces the original when an array changes length.
+func (s *Settings) deletePath(obj interface{}, path []segment) (interface{}, error) {
+	name := path[0].name
 	if len(path) == 1 {
-		parent = value
-	} else {
-		parent, err = createPath(value, path[:len(path)-1])
+		result, err := s.deleteElement(obj, name)
+		return result, segmentError(name, err)
+	}
+	child, err := s.getElement(obj, name)
+	if err == IndexError {
+		return obj, nil
+	} else if err != nil {
+		return nil, segmentError(name, err)
+	}
+	if child, err = s.deletePath(child, path[1:]); err != nil {
+		return nil, err
+	}
+	result, err := s.setElement(obj, name, child)
+	return result, segmentError(name, err)
+}
+
+// Return the index of the last filter in the path or -1 if there is none.
+func lastFilter(path []segment) int {
+	for n := len(path) - 1; n >= 0; n-- {
+		if path[n].filter != nil {
+			return n
+		}
 	}
-	return
+	return -1
+}
+
+// Set a value in every array element selected by the filter at index `n` of
+// the path. Missing objects after the filter are created.
+func (s *Settings) setFiltered(path []segment, n int, value interface{}) error {
+	matches := []Match{}
+	s.query(s.Values, path[:n], KeyPath{}, map[string]bool{}, &matches)
+	f, rest := path[n].filter, path[n+1:]
+	found := false
+	for _, match := range matches {
+		items, ok := match.Value.([]interface{})
+		if !ok {
+			return typeError("array", match.Value)
+		}
+		for _, index := range f.selectIndices(s, items) {
+			found = true
+			var err error
+			if len(rest) == 0 {
+				_, err = s.setElement(items, strconv.Itoa(index), getInterface(value))
+			} else {
+				items[index], err = s.setPath(items[index], rest, getInterface(value))
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if !found && !f.all {
+		return KeyError
+	}
+	return nil
 }
 
 // Set a value in the settings object. This overrides all keys in the path that
-// are not already objects. The following errors may be returned:
+// are not already objects. Negative array indices count back from the end of
+// the array and an index equal to the length of the array appends to it. A
+// slice such as `items.1:3` replaces those elements with the elements of an
+// array value. If the key contains filters the value is set in each selected
+// array element. A `[?...]` filter which selects no elements is not an error.
+// Errors are returned as a *PathError wrapping one of the following:
 //
 // ObjectError - a child object is not a `map[interface{}]interface{}` or `[]interface{}`
 // IndexError - a key cannot be converted to an integer for a child array
+// KeyError - a filter without `?` selects no elements
 // RangeError - the index is out of range for a child array
+// SyntaxError - the key is malformed
+// TypeError - the value set on a slice or a filtered value is not an array
 func (s *Settings) Set(key string, value interface{}) error {
 	if s.Values == nil {
 		s.Values = make(map[interface{}]interface{})
 	}
-	names := strings.Split(key, ".")
-	if parent, err := createPath(s.Values, names[:len(names)-1]); err == nil {
-		return setElement(parent, names[len(names)-1], getInterface(value))
-	} else {
-		return err
+	names, err := s.parseKey(key)
+	if err != nil {
+		return s.pathError("set", key, err)
+	} else if len(names) == 0 {
+		return s.pathError("set", key, KeyError)
 	}
+	if n := lastFilter(names); n != -1 {
+		return s.pathError("set", key, s.setFiltered(names, n, value))
+	}
+	_, err = s.setPath(s.Values, names, getInterface(value))
+	return s.pathError("set", key, err)
 }
 
 // Append a value to an array. Creates an array at that location if it does not
-// exist. The following errors may be returned:
+// exist. Errors are returned as a *PathError wrapping one of the following:
 //
 // ObjectError - a child object is not a `map[interface{}]interface{}` or `[]interface{}`
 // IndexError - a key cannot be converted to an integer for a child array
+// RangeError - the index is out of range for a child array
+// SyntaxError - the key is malformed or contains a filter
 func (s *Settings) Append(key string, value interface{}) error {
-	var ok bool
-	var err error
-	var parent, arrayObj interface{}
-	var array []interface{}
-
 	if s.Values == nil {
 		s.Values = make(map[interface{}]interface{})
 	}
@@ -147,60 +280,51 @@ func (s *Settings) Append(key string, value interface{}) error {
 		value = obj.Values
 	}
 
-	names := strings.Split(key, ".")
-	name := names[len(names)-1]
-	if parent, err = getParent(s.Values, names); err != nil {
-		return err
+	names, err := s.parseKey(key)
+	if err != nil {
+		return s.pathError("append", key, err)
+	} else if len(names) == 0 {
+		return s.pathError("append", key, KeyError)
+	} else if lastFilter(names) != -1 {
+		return s.pathError("append", key, SyntaxError)
 	}
 
-	if arrayObj, err = getElement(parent, name); err == IndexError {
-		array = make([]interface{}, 0)
-	} else if err != nil {
-		return err
-	} else if array, ok = arrayObj.([]interface{}); !ok {
-		array = make([]interface{}, 0)
+	var array []interface{}
+	var obj interface{} = s.Values
+	for _, seg := range names {
+		if obj, err = s.getElement(obj, seg.name); err == IndexError || err == RangeError {
+			break
+		} else if err != nil {
+			return s.pathError("append", key, segmentError(seg.name, err))
+		}
+	}
+	if err == nil {
+		array, _ = obj.([]interface{})
 	}
 
-	return setElement(parent, name, append(array, getInterface(value)))
+	_, err = s.setPath(s.Values, names, append(array, getInterface(value)))
+	return s.pathError("append", key, err)
 }
 
-// Delete a key. May return an error on failure. A non-existent key is not an error case.
+// Delete a key. May return an error on failure. A non-existent key is not an
+// error case. Negative array indices count back from the end of the array and
+// a slice such as `items.1:3` deletes those elements. Errors are returned as a
+// *PathError wrapping one of the following:
 //
 // ObjectError - a child object is not a `map[interface{}]interface{}` or `[]interface{}`
-// IndexError - a key cannot be converted to an integer for a child array
+// RangeError - the index is out of range for a child array
+// SyntaxError - the key is malformed or contains a filter
 func (s *Settings) Delete(key string) error {
-	var err error
-
-	names := strings.Split(key, ".")
-	name := names[len(names)-1]
-	if len(names) == 1 {
-		if _, ok := s.Values[name]; ok {
-			delete(s.Values, name)
-		}
-	} else {
-		var child, parent interface{}
-		path := names[:len(names)-1]
-		childName := names[len(path)-1]
-		if parent, err = getParent(s.Values, path); err != nil {
-			return err
-		}
-		if child, err = getElement(parent, childName); err != nil {
-			return err
-		}
-
-		switch child.(type) {
-		case map[interface{}]interface{}:
-			delete(child.(map[interface{}]interface{}), name)
-		case []interface{}:
-			if n, err := strconv.Atoi(name); err == nil {
-				array := child.([]interface{})
-				err = setElement(parent, childName, append(array[:n], array[n+1:]...))
-			} else {
-				err = IndexError
-			}
-		default:
-			err = ObjectError
-		}
+	names, err := s.parseKey(key)
+	if err != nil {
+		return s.pathError("delete", key, err)
+	} else if len(names) == 0 {
+		return s.pathError("delete", key, KeyError)
+	} else if lastFilter(names) != -1 {
+		return s.pathError("delete", key, SyntaxError)
+	}
+	if _, err = s.deletePath(s.Values, names); errors.Is(err, IndexError) {
+		return nil
 	}
-	return err
+	return s.pathError("delete", key, err)
 }
@@ -1,6 +1,7 @@
 package settings
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 )
@@ -218,7 +219,7 @@ func TestDelete(t *testing.T) {
 	key = "value"
 	settings.Set(key, "one")
 	if err = settings.Delete(key); err == nil {
-		if value, err := settings.Raw(key); err != KeyError {
+		if value, err := settings.Raw(key); !errors.Is(err, KeyError) {
 			t.Errorf("%s not deleted: %v (%s)\n", key, value, err)
 		}
 	} else {
@@ -228,7 +229,7 @@ func TestDelete(t *testing.T) {
 	// delete item in root map
 	key = "values.bool"
 	if err = settings.Delete(key); err == nil {
-		if value, err := settings.Raw(key); err != KeyError {
+		if value, err := settings.Raw(key); !errors.Is(err, KeyError) {
 			t.Errorf("%s not deleted: %v (%s)\n", key, value, err)
 		}
 	} else {
@@ -238,7 +239,7 @@ func TestDelete(t *testing.T) {
 	// delete item in root array
 	key = "string-array.one"
 	if err = settings.Delete(key); err == nil {
-		if value, err := settings.Raw(key); err != KeyError {
+		if value, err := settings.Raw(key); !errors.Is(err, IndexError) {
 			t.Errorf("%s not deleted: %v (%s)\n", key, value, err)
 		}
 	} else {
@@ -248,7 +249,7 @@ func TestDelete(t *testing.T) {
 	// delete item in nested map
 	key = "settings-array.1.value"
 	if err = settings.Delete(key); err == nil {
-		if value, err := settings.Raw(key); err != KeyError {
+		if value, err := settings.Raw(key); !errors.Is(err, KeyError) {
 			t.Errorf("%s not deleted: %v (%s)\n", key, value, err)
 		}
 	} else {
@@ -272,3 +273,217 @@ func TestDelete(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestSetKeyPath(t *testing.T) {
+	settings := New()
+	key := NewKeyPath("hosts", "api.example.com", "port").String()
+	if err := settings.Set(key, 8443); err != nil {
+		t.Error(err)
+	}
+	want := map[interface{}]interface{}{
+		"hosts": map[interface{}]interface{}{
+			"api.example.com": map[interface{}]interface{}{"port": 8443},
+		},
+	}
+	if !reflect.DeepEqual(want, settings.Values) {
+		t.Errorf("%v != %v", want, settings.Values)
+	}
+
+	if err := settings.Append(`hosts."api.example.com".aliases`, "api"); err != nil {
+		t.Error(err)
+	}
+	if value, err := settings.StringArray(`hosts."api.example.com".aliases`); err != nil || !reflect.DeepEqual(value, []string{"api"}) {
+		t.Errorf("%v != %v (%v)", []string{"api"}, value, err)
+	}
+
+	if err := settings.Delete(`hosts."api.example.com".port`); err != nil {
+		t.Error(err)
+	}
+	if settings.Has(key) {
+		t.Errorf("key %s was not deleted", key)
+	}
+
+	for _, key := range []string{`"a`, `a\`} {
+		if err := settings.Set(key, 1); !errors.Is(err, SyntaxError) {
+			t.Errorf("key %s is valid", key)
+		}
+	}
+
+	// json pointers
+	settings.Pointers = true
+	if err := settings.Set("/a~1b/c", "d"); err != nil {
+		t.Error(err)
+	}
+	if value, err := settings.String("/a~1b/c"); err != nil || value != "d" {
+		t.Errorf("%v != %v (%v)", "d", value, err)
+	}
+	if err := settings.Set("", "d"); !errors.Is(err, KeyError) {
+		t.Errorf("key %s is valid", "")
+	}
+}
+
+func TestSetIndex(t *testing.T) {
+	type test struct {
+		key   string
+		value interface{}
+		want  []interface{}
+		err   error
+	}
+
+	tests := []test{
+		{"items.-1", "z", []interface{}{"a", "b", "z"}, nil},
+		{"items.3", "d", []interface{}{"a", "b", "c", "d"}, nil},
+		{"items.4", "e", []interface{}{"a", "b", "c"}, RangeError},
+		{"items.-4", "e", []interface{}{"a", "b", "c"}, RangeError},
+		{"items.x", "e", []interface{}{"a", "b", "c"}, IndexError},
+		{"items.1:3", []string{"x"}, []interface{}{"a", "x"}, nil},
+		{"items.3:", []string{"d", "e"}, []interface{}{"a", "b", "c", "d", "e"}, nil},
+		{"items.:0", []string{"z"}, []interface{}{"z", "a", "b", "c"}, nil},
+		{"items.1:2", "x", []interface{}{"a", "b", "c"}, TypeError},
+		{"items.2:1", []string{}, []interface{}{"a", "b", "c"}, RangeError},
+	}
+
+	for _, test := range tests {
+		settings, _ := Parse([]byte(`items: [a, b, c]`))
+		if err := settings.Set(test.key, test.value); !errors.Is(err, test.err) {
+			t.Errorf("%s: %v != %v", test.key, err, test.err)
+		}
+		if value, _ := settings.Raw("items"); !reflect.DeepEqual(value, test.want) {
+			t.Errorf("%s: %v != %v", test.key, value, test.want)
+		}
+	}
+
+	// set within an element appended to a nested array
+	settings, _ := Parse([]byte(`servers: [{name: a}]`))
+	if err := settings.Set("servers.1.name", "b"); err != nil {
+		t.Error(err)
+	}
+	if value, err := settings.String("servers.-1.name"); err != nil || value != "b" {
+		t.Errorf("%v != %v (%v)", "b", value, err)
+	}
+	if err := settings.Set("servers.0:1.0.name", "c"); err != nil {
+		t.Error(err)
+	}
+	if value, _ := settings.String("servers.0.name"); value != "c" {
+		t.Errorf("%v != %v", "c", value)
+	}
+
+	// append to an array nested in an array
+	settings, _ = Parse([]byte(`matrix: [[1], [2]]`))
+	if err := settings.Append("matrix.-1", 3); err != nil {
+		t.Error(err)
+	}
+	if value, _ := settings.IntArray("matrix.1"); !reflect.DeepEqual(value, []int{2, 3}) {
+		t.Errorf("%v != %v", []int{2, 3}, value)
+	}
+}
+
+func TestDeleteIndex(t *testing.T) {
+	type test struct {
+		key  string
+		want []interface{}
+		err  error
+	}
+
+	tests := []test{
+		{"items.-1", []interface{}{"a", "b", "c"}, nil},
+		{"items.0", []interface{}{"b", "c", "d"}, nil},
+		{"items.1:3", []interface{}{"a", "d"}, nil},
+		{"items.-2:", []interface{}{"a", "b"}, nil},
+		{"items.4", []interface{}{"a", "b", "c", "d"}, RangeError},
+		{"items.1:5", []interface{}{"a", "b", "c", "d"}, RangeError},
+		{"items.x", []interface{}{"a", "b", "c", "d"}, nil},
+	}
+
+	for _, test := range tests {
+		settings, _ := Parse([]byte(`items: [a, b, c, d]`))
+		if err := settings.Delete(test.key); !errors.Is(err, test.err) {
+			t.Errorf("%s: %v != %v", test.key, err, test.err)
+		}
+		if value, _ := settings.Raw("items"); !reflect.DeepEqual(value, test.want) {
+			t.Errorf("%s: %v != %v", test.key, value, test.want)
+		}
+	}
+
+	// delete from an array nested in an array
+	settings, _ := Parse([]byte(`matrix: [[1, 2], [3, 4]]`))
+	if err := settings.Delete("matrix.-1.0"); err != nil {
+		t.Error(err)
+	}
+	if value, _ := settings.Raw("matrix"); !reflect.DeepEqual(value, []interface{}{[]interface{}{1, 2}, []interface{}{4}}) {
+		t.Errorf("invalid matrix: %v", value)
+	}
+}
+
+func TestSetScalarKeys(t *testing.T) {
+	settings, _ := Parse([]byte(`errors:
+  404: not-found.html
+  500: error.html
+flags:
+  true: on
+point: {x: 1, y: 2}`))
+
+	if err := settings.Set("errors.404", "missing.html"); err != nil {
+		t.Error(err)
+	}
+	if err := settings.Set("flags.true", "yes"); err != nil {
+		t.Error(err)
+	}
+	if err := settings.Set("errors.403", "forbidden.html"); err != nil {
+		t.Error(err)
+	}
+	if err := settings.Delete("errors.500"); err != nil {
+		t.Error(err)
+	}
+	if err := settings.Set("point.y", 3); err != nil {
+		t.Error(err)
+	}
+
+	want := map[interface{}]interface{}{
+		404:   "missing.html",
+		"403": "forbidden.html",
+	}
+	if value, _ := settings.Raw("errors"); !reflect.DeepEqual(value, want) {
+		t.Errorf("%v != %v", want, value)
+	}
+	if value, _ := settings.Raw("flags"); !reflect.DeepEqual(value, map[interface{}]interface{}{true: "yes"}) {
+		t.Errorf("invalid flags: %v", value)
+	}
+	if value, _ := settings.Raw("point"); !reflect.DeepEqual(value, map[interface{}]interface{}{"x": 1, true: 3}) {
+		t.Errorf("invalid point: %v", value)
+	}
+}
+
+func TestSetFoldKeys(t *testing.T) {
+	settings, _ := Parse([]byte(`pool:
+  max_conns: 10
+dup:
+  maxConns: 1
+  max-conns: 2`))
+	settings.FoldKeys = true
+
+	if err := settings.Set("pool.maxConns", 20); err != nil {
+		t.Error(err)
+	}
+	if err := settings.Set("pool.min-conns", 1); err != nil {
+		t.Error(err)
+	}
+	want := map[interface{}]interface{}{"max_conns": 20, "min-conns": 1}
+	if value, _ := settings.Raw("pool"); !reflect.DeepEqual(value, want) {
+		t.Errorf("%v != %v", want, value)
+	}
+
+	if err := settings.Delete("POOL.MinConns"); err != nil {
+		t.Error(err)
+	}
+	if settings.Has("pool.min_conns") {
+		t.Error("pool.min_conns not deleted")
+	}
+
+	if err := settings.Set("dup.max_conns", 3); !errors.Is(err, ConflictError) {
+		t.Errorf("conflicting keys did not cause a ConflictError: %v", err)
+	}
+	if err := settings.Delete("dup.max_conns"); !errors.Is(err, ConflictError) {
+		t.Errorf("conflicting keys did not cause a ConflictError: %v", err)
+	}
+}
@@ -6,23 +6,161 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"sync"
+	"time"
 )
 
+// Settings holds a tree of configuration values. Objects returned by the
+// Object getters are views into the tree of the object they were obtained
+// from: they share its values so changes made with Set, Append and Delete
+// through a view are visible through its parent and root and vice versa. A
+// view is detached when its own object is replaced or deleted in the parent,
+// after which changes through it are no longer visible in the parent.
 type Settings struct {
+	// Key is the absolute key of the object within its root. It is empty for
+	// the root object.
 	Key    string
 	Values map[interface{}]interface{}
+
+	// Coerce enables lenient type conversion in the string, integer and float
+	// getters. Numeric strings are converted to numbers, integral floats to
+	// integers and scalars to strings. Objects returned by the Object getters
+	// inherit this setting.
+	Coerce bool
+
+	// HumanInts enables parsing of human friendly integer strings in the
+	// integer getters. See ParseHumanInt for the accepted formats. Objects
+	// returned by the Object getters inherit this setting.
+	HumanInts bool
+
+	// File is the absolute path of the file the settings were loaded from.
+	// Relative paths returned by the Path getters are resolved against its
+	// directory. It is empty if the settings were not loaded from a file.
+	File string
+
+	// SizeMode selects how the Size getters interpret size suffixes. The zero
+	// value is SizeCompat.
+	SizeMode SizeMode
+
+	// DurationUnit is the unit of bare numbers read by the Duration getters.
+	// The zero value means seconds.
+	DurationUnit time.Duration
+
+	// Pointers selects RFC 6901 JSON Pointer syntax for keys, e.g.
+	// `/hosts/api.example.com/port`, instead of dotted keys. Objects returned
+	// by the Object getters inherit this setting.
+	Pointers bool
+
+	// FoldKeys enables case and style insensitive key matching. Keys match
+	// regardless of case and of `_` and `-` separators, so `max_conns`,
+	// `max-conns` and `maxConns` name the same value. A ConflictError is
+	// returned when more than one spelling of a key exists. Objects returned by
+	// the Object getters inherit this setting.
+	FoldKeys bool
+
+	// StrictDflt makes the Dflt getters return their default only when the
+	// value is missing or null. Any other error, such as a value which cannot
+	// be converted, is passed to DfltHook before the default is returned. If
+	// DfltHook is nil the error is logged with the standard logger instead.
+	// Objects returned by the Object getters inherit both settings.
+	StrictDflt bool
+	DfltHook   func(err error)
+
+	// compiled regular expressions keyed by pattern; only used on the root
+	regexps sync.Map
+
+	// the source positions of the values of a parsed root object
+	positions *posNode
+
+	// the object this view was obtained from and the root of its tree
+	parent *Settings
+	root   *Settings
+}
+
+// Create a view of the object at `key` which inherits the options of its
+// parent.
+func (s *Settings) child(key string, values map[interface{}]interface{}) *Settings {
+	return &Settings{
+		Key:          s.FullKey(key),
+		Values:       values,
+		Coerce:       s.Coerce,
+		HumanInts:    s.HumanInts,
+		File:         s.File,
+		SizeMode:     s.SizeMode,
+		DurationUnit: s.DurationUnit,
+		Pointers:     s.Pointers,
+		FoldKeys:     s.FoldKeys,
+		StrictDflt:   s.StrictDflt,
+		DfltHook:     s.DfltHook,
+		parent:       s,
+		root:         s.Root(),
+	}
+}
+
+// Root returns the root of the tree this object is a view into. A root object
+// returns itself.
+func (s *Settings) Root() *Settings {
+	if s.root == nil {
+		return s
+	}
+	return s.root
+}
+
+// Parent returns the object this view was obtained from or nil for a root
+// object.
+func (s *Settings) Parent() *Settings {
+	return s.parent
+}
+
+// FullKey returns the absolute key of a key relative to this object. The
+// result may be passed to the getters of the root object.
+func (s *Settings) FullKey(key string) string {
+	switch {
+	case s.Key == "":
+		return key
+	case key == "":
+		return s.Key
+	case s.Pointers:
+		return s.Key + key
+	default:
+		return s.Key + "." + key
+	}
+}
+
+// Return an error as a *PathError for an operation on a key relative to this
+// object. The source position of the key is recorded if it is known. Path
+// errors which already have a key are returned as is.
+func (s *Settings) pathError(op, key string, err error) error {
+	if err == nil {
+		return nil
+	}
+	pe, ok := err.(*PathError)
+	if !ok {
+		pe = &PathError{Err: err}
+	} else if pe.Key != "" {
+		return pe
+	}
+	pe.Op, pe.Key = op, s.FullKey(key)
+	pe.Position, _ = s.Position(key)
+	return pe
+}
+
+// Return an error from a getter as a *PathError.
+func (s *Settings) keyError(key string, err error) error {
+	return s.pathError("get", key, err)
 }
 
 // New returns the pointer to a freshly allocated settings struct.
 func New() *Settings {
-	return &Settings{"", map[interface{}]interface{}{}}
+	return &Settings{Key: "", Values: map[interface{}]interface{}{}}
 }
 
 // Parse the provided YAML into a new Settings object.
 func Parse(data []byte) (*Settings, error) {
 	values := make(map[interface{}]interface{})
 	if err := yaml.Unmarshal(data, values); err == nil {
-		return &Settings{Values: values}, nil
+		return &Settings{Values: values, positions: scanPositions(data)}, nil
 	} else {
 		return nil, err
 	}
@@ -37,11 +175,20 @@ func Read(reader io.Reader) (*Settings, error) {
 	}
 }
 
-// Load and parse settings from the file at the provided path.
+// Load and parse settings from the file at the provided path. The absolute
+// path of the file is recorded in the File field.
 func Load(path string) (*Settings, error) {
-	if file, err := os.Open(path); err == nil {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if file, err := os.Open(abs); err == nil {
 		defer file.Close()
-		return Read(file)
+		settings, err := Read(file)
+		if err == nil {
+			settings.File = abs
+		}
+		return settings, err
 	} else {
 		return nil, err
 	}
@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -85,6 +86,16 @@ size-map:
   gb: 6g
   tb: 2tb
 
+regexp-array:
+- ^one$
+- t[wo]+
+
+regexp-map:
+  one: ^one$
+  two: t[wo]+
+
+invalid-regexp: a(b
+
 settings-array:
 - name: one
   value: I won!
@@ -101,6 +112,24 @@ mixed-array:
 - one
 - 2
 
+coerce:
+  string: "8080"
+  float: 8080.0
+  fraction: 1.5
+  integer: 8080
+  bool: true
+  word: eighty
+
+coerce-array:
+- "1"
+- 2.0
+- 3
+
+coerce-map:
+  one: "1"
+  two: 2.0
+  three: 3
+
 values:
   bool: true
   integer: 1
@@ -196,6 +225,9 @@ func TestLoad(t *testing.T) {
 		if !reflect.DeepEqual(have.Values, want) {
 			t.Errorf("%v != %v", want, have)
 		}
+		if have.File != path {
+			t.Errorf("%v != %v", path, have.File)
+		}
 	} else {
 		t.Error(err)
 	}
@@ -210,3 +242,95 @@ func TestLoadOrExit(t *testing.T) {
 		t.Errorf("%v != %v", want, have)
 	}
 }
+
+func TestViews(t *testing.T) {
+	settings, _ := Parse([]byte(`db:
+  primary:
+    host: localhost
+    port: nope
+  replicas:
+  - host: replica
+servers:
+  web:
+    window:
+      from: "09:00"
+      to: "25:00"`))
+
+	db, err := settings.Object("db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	primary, err := db.Object("primary")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if primary.Key != "db.primary" {
+		t.Errorf("%s != %s", "db.primary", primary.Key)
+	}
+	if primary.Parent() != db || db.Parent() != settings || settings.Parent() != nil {
+		t.Error("invalid parents")
+	}
+	if primary.Root() != settings || db.Root() != settings || settings.Root() != settings {
+		t.Error("invalid roots")
+	}
+	if key := primary.FullKey("port"); key != "db.primary.port" {
+		t.Errorf("%s != %s", "db.primary.port", key)
+	}
+	if key := primary.FullKey(""); key != "db.primary" {
+		t.Errorf("%s != %s", "db.primary", key)
+	}
+	if key := settings.FullKey("db"); key != "db" {
+		t.Errorf("%s != %s", "db", key)
+	}
+
+	replicas, err := db.ObjectArray("replicas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replicas[0].Key != "db.replicas.0" || replicas[0].Parent() != db || replicas[0].Root() != settings {
+		t.Errorf("invalid replica view %s", replicas[0].Key)
+	}
+
+	// errors report the absolute key
+	web, _ := settings.Object("servers.web")
+	if _, err := web.Window("window"); err == nil || !strings.HasPrefix(err.Error(), "11:11: servers.web.window.to: ") {
+		t.Errorf("error does not contain the absolute key: %v", err)
+	}
+	if _, err := primary.Enum("host", "a", "b"); err == nil || err.(*PathError).Key != "db.primary.host" {
+		t.Errorf("error does not contain the absolute key: %v", err)
+	}
+
+	// writes through views are visible in the root and vice versa
+	if err := primary.Set("user", "admin"); err != nil {
+		t.Error(err)
+	}
+	if value, _ := settings.String("db.primary.user"); value != "admin" {
+		t.Errorf("%s != %s", "admin", value)
+	}
+	if err := settings.Delete("db.primary.port"); err != nil {
+		t.Error(err)
+	}
+	if primary.Has("port") {
+		t.Error("delete not visible in view")
+	}
+	if err := replicas[0].Append("tags", "ro"); err != nil {
+		t.Error(err)
+	}
+	if value, _ := settings.StringArray("db.replicas.0.tags"); len(value) != 1 {
+		t.Errorf("append not visible in root: %v", value)
+	}
+
+	// replacing the object detaches the view
+	settings.Set("db.primary", map[string]string{"host": "remote"})
+	if value, _ := primary.String("host"); value != "localhost" {
+		t.Errorf("%s != %s", "localhost", value)
+	}
+
+	// json pointers
+	settings.Pointers = true
+	primary, _ = settings.Object("/db/primary")
+	if key := primary.FullKey("/host"); key != "/db/primary/host" {
+		t.Errorf("%s != %s", "/db/primary/host", key)
+	}
+}
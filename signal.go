@@ -0,0 +1,84 @@
+//go:build unix
+
+package settings
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+var signals = map[string]syscall.Signal{
+	"ABRT":   syscall.SIGABRT,
+	"ALRM":   syscall.SIGALRM,
+	"BUS":    syscall.SIGBUS,
+	"CHLD":   syscall.SIGCHLD,
+	"CONT":   syscall.SIGCONT,
+	"FPE":    syscall.SIGFPE,
+	"HUP":    syscall.SIGHUP,
+	"ILL":    syscall.SIGILL,
+	"INT":    syscall.SIGINT,
+	"IO":     syscall.SIGIO,
+	"KILL":   syscall.SIGKILL,
+	"PIPE":   syscall.SIGPIPE,
+	"PROF":   syscall.SIGPROF,
+	"QUIT":   syscall.SIGQUIT,
+	"SEGV":   syscall.SIGSEGV,
+	"STOP":   syscall.SIGSTOP,
+	"SYS":    syscall.SIGSYS,
+	"TERM":   syscall.SIGTERM,
+	"TRAP":   syscall.SIGTRAP,
+	"TSTP":   syscall.SIGTSTP,
+	"TTIN":   syscall.SIGTTIN,
+	"TTOU":   syscall.SIGTTOU,
+	"URG":    syscall.SIGURG,
+	"USR1":   syscall.SIGUSR1,
+	"USR2":   syscall.SIGUSR2,
+	"VTALRM": syscall.SIGVTALRM,
+	"WINCH":  syscall.SIGWINCH,
+	"XCPU":   syscall.SIGXCPU,
+	"XFSZ":   syscall.SIGXFSZ,
+}
+
+// Parse a signal name or number. Names are case insensitive and the `SIG`
+// prefix is optional, so `SIGTERM`, `term` and `15` are equivalent.
+func ParseSignal(s string) (syscall.Signal, error) {
+	s = strings.TrimSpace(s)
+	if n, err := strconv.Atoi(s); err == nil && n > 0 {
+		return syscall.Signal(n), nil
+	}
+	name := strings.TrimPrefix(strings.ToUpper(s), "SIG")
+	if signal, ok := signals[name]; ok {
+		return signal, nil
+	}
+	return 0, fmt.Errorf("unknown signal %q", s)
+}
+
+// Get a signal. The value may be a signal name or number.
+func (s *Settings) Signal(key string) (syscall.Signal, error) {
+	if value, err := s.Raw(key); err == nil {
+		switch value.(type) {
+		case int:
+			if n := value.(int); n > 0 {
+				return syscall.Signal(n), nil
+			}
+		case string:
+			sig, err := ParseSignal(value.(string))
+			return sig, s.keyError(key, err)
+		}
+		return 0, s.keyError(key, typeError("signal", value))
+	} else {
+		return 0, err
+	}
+}
+
+// Get a signal. Return `dflt` if an error occurs.
+func (s *Settings) SignalDflt(key string, dflt syscall.Signal) syscall.Signal {
+	if value, err := s.Signal(key); err == nil {
+		return value
+	} else {
+		s.dfltError(key, err)
+		return dflt
+	}
+}
@@ -0,0 +1,58 @@
+//go:build unix
+
+package settings
+
+import (
+	"errors"
+	"syscall"
+	"testing"
+)
+
+func TestParseSignal(t *testing.T) {
+	type test struct {
+		str    string
+		signal syscall.Signal
+	}
+
+	tests := []test{
+		{"SIGTERM", syscall.SIGTERM},
+		{"TERM", syscall.SIGTERM},
+		{"sighup", syscall.SIGHUP},
+		{" usr1 ", syscall.SIGUSR1},
+		{"9", syscall.SIGKILL},
+	}
+
+	for _, test := range tests {
+		if signal, err := ParseSignal(test.str); err != nil {
+			t.Errorf("error parsing %s: %s", test.str, err)
+		} else if signal != test.signal {
+			t.Errorf("signal is invalid: %v != %v", signal, test.signal)
+		}
+	}
+
+	errors := []string{"", "SIGNOPE", "0", "-1"}
+	for _, str := range errors {
+		if _, err := ParseSignal(str); err == nil {
+			t.Errorf("no error parsing %s", str)
+		}
+	}
+}
+
+func TestSignal(t *testing.T) {
+	settings, _ := Parse([]byte(`reload: SIGHUP
+stop: 15
+invalid: 1.5`))
+
+	if value, err := settings.Signal("reload"); err != nil || value != syscall.SIGHUP {
+		t.Errorf("%v != %v (%v)", syscall.SIGHUP, value, err)
+	}
+	if value, err := settings.Signal("stop"); err != nil || value != syscall.SIGTERM {
+		t.Errorf("%v != %v (%v)", syscall.SIGTERM, value, err)
+	}
+	if _, err := settings.Signal("invalid"); !errors.Is(err, TypeError) {
+		t.Errorf("key %s is valid", "invalid")
+	}
+	if value := settings.SignalDflt("nope", syscall.SIGINT); value != syscall.SIGINT {
+		t.Errorf("%v != %v", syscall.SIGINT, value)
+	}
+}
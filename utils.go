@@ -1,44 +1,407 @@
 package settings
 
 import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"os/user"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"syscall"
 )
 
-// Parse a size string into a number of bytes. Valid suffixes are b, k, kb, m,
-// mb, g, gb, t, and tb. Case is ignored. A lack of suffix indicates bytes.
+// SizeMode selects how size suffixes are interpreted.
+type SizeMode int
+
+const (
+	// SizeCompat treats SI and IEC suffixes alike as powers of 1024, so `k`,
+	// `kb` and `kib` all mean 1024 bytes.
+	SizeCompat SizeMode = iota
+	// SizeStrict treats SI suffixes (`k`, `kb`, `mb`, ...) as powers of 1000
+	// and IEC suffixes (`kib`, `mib`, ...) as powers of 1024.
+	SizeStrict
+)
+
+type sizeUnit struct {
+	symbol string
+	factor int64
+}
+
+var iecUnits = []sizeUnit{
+	{"EiB", 1 << 60},
+	{"PiB", 1 << 50},
+	{"TiB", 1 << 40},
+	{"GiB", 1 << 30},
+	{"MiB", 1 << 20},
+	{"KiB", 1 << 10},
+}
+
+var siUnits = []sizeUnit{
+	{"EB", 1e18},
+	{"PB", 1e15},
+	{"TB", 1e12},
+	{"GB", 1e9},
+	{"MB", 1e6},
+	{"kB", 1e3},
+}
+
+var sizePrefixes = "kmgtpe"
+
+// Return the factor for a lower case size suffix.
+func (m SizeMode) factor(suffix string) (int64, bool) {
+	suffix = strings.TrimSuffix(suffix, "b")
+	if suffix == "" {
+		return 1, true
+	}
+
+	iec := strings.HasSuffix(suffix, "i")
+	suffix = strings.TrimSuffix(suffix, "i")
+	if len(suffix) != 1 {
+		return 0, false
+	}
+	power := strings.Index(sizePrefixes, suffix) + 1
+	if power == 0 {
+		return 0, false
+	}
+
+	var base, factor int64 = 1024, 1
+	if m == SizeStrict && !iec {
+		base = 1000
+	}
+	for i := 0; i < power; i++ {
+		factor *= base
+	}
+	return factor, true
+}
+
+// Parse a size string into a number of bytes. The number may contain a
+// fractional part and is rounded to the nearest byte. Valid suffixes are b, k,
+// kb, kib, m, mb, mib, g, gb, gib, t, tb, tib, p, pb, pib, e, eb and eib. Case
+// is ignored. A lack of suffix indicates bytes.
+func (m SizeMode) Parse(s string) (int64, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	end := strings.IndexFunc(s, func(r rune) bool {
+		return !strings.ContainsRune("0123456789.+-", r)
+	})
+	if end < 0 {
+		end = len(s)
+	}
+	number, suffix := s[:end], strings.TrimSpace(s[end:])
+
+	factor, ok := m.factor(suffix)
+	if !ok {
+		return 0, fmt.Errorf("invalid size suffix in %q", s)
+	}
+	value, ok := new(big.Rat).SetString(number)
+	if !ok || number == "" {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+
+	// round half away from zero to the nearest byte
+	value.Mul(value, new(big.Rat).SetInt64(factor))
+	half := big.NewRat(1, 2)
+	if value.Sign() < 0 {
+		half.Neg(half)
+	}
+	value.Add(value, half)
+	bytes := new(big.Int).Quo(value.Num(), value.Denom())
+	if !bytes.IsInt64() {
+		return 0, fmt.Errorf("size %q overflows int64", s)
+	}
+	return bytes.Int64(), nil
+}
+
+// Format a number of bytes using the largest unit which represents it exactly
+// with no more than three decimal places. IEC units are always used in
+// compatibility mode. Strict mode also considers SI units and picks the
+// shorter result.
+func (m SizeMode) Format(size int64) string {
+	best := formatSizeUnits(size, iecUnits)
+	if m == SizeStrict {
+		if si := formatSizeUnits(size, siUnits); len(si) < len(best) {
+			best = si
+		}
+	}
+	return best
+}
+
+// Format a size using the first unit in `units` which represents it exactly.
+func formatSizeUnits(size int64, units []sizeUnit) string {
+	abs := new(big.Int).Abs(big.NewInt(size))
+	for _, unit := range units {
+		factor := big.NewInt(unit.factor)
+		if abs.Cmp(factor) < 0 {
+			continue
+		}
+		value := new(big.Rat).SetFrac(big.NewInt(size), factor)
+		scaled := new(big.Rat).Mul(value, big.NewRat(1000, 1))
+		if scaled.IsInt() {
+			str := strings.TrimRight(value.FloatString(3), "0")
+			return strings.TrimSuffix(str, ".") + unit.symbol
+		}
+	}
+	return strconv.FormatInt(size, 10) + "B"
+}
+
+// Parse a size string into a number of bytes using SizeCompat.
 func ParseSize(s string) (int64, error) {
-	type size struct {
-		symbol string
-		factor int64
+	return SizeCompat.Parse(s)
+}
+
+// Format a number of bytes using SizeCompat.
+func FormatSize(size int64) string {
+	return SizeCompat.Format(size)
+}
+
+// Expand a leading `~` or `~user` and environment variables in a path. Relative
+// paths are then resolved against `dir` if it is not empty.
+func ExpandPath(path, dir string) (string, error) {
+	if strings.HasPrefix(path, "~") {
+		name := path[1:]
+		rest := ""
+		if n := strings.IndexRune(name, '/'); n >= 0 {
+			name, rest = name[:n], name[n:]
+		}
+
+		var home string
+		if name == "" {
+			if h, err := os.UserHomeDir(); err == nil {
+				home = h
+			} else {
+				return "", err
+			}
+		} else {
+			if u, err := user.Lookup(name); err == nil {
+				home = u.HomeDir
+			} else {
+				return "", err
+			}
+		}
+		path = home + rest
 	}
 
-	sizes := []size{
-		{"kb", 1024},
-		{"mb", 1048576},
-		{"gb", 1073741824},
-		{"tb", 1099511627776},
-		{"b", 1},
-		{"k", 1024},
-		{"m", 1048576},
-		{"g", 1073741824},
-		{"t", 1099511627776},
+	path = os.ExpandEnv(path)
+	if dir != "" && !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	return filepath.Clean(path), nil
+}
+
+// PathCheck is a condition which a path must satisfy.
+type PathCheck int
+
+const (
+	// The path must exist.
+	PathExists PathCheck = 1 << iota
+	// The path must be a directory.
+	PathIsDir
+	// The path must be a regular file.
+	PathIsFile
+	// The path must be readable by the current process.
+	PathReadable
+)
+
+// Verify that the path satisfies all of the checks.
+func checkPath(path string, checks PathCheck) error {
+	if checks == 0 {
+		return nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if checks&PathIsDir != 0 && !info.IsDir() {
+		return &os.PathError{Op: "check", Path: path, Err: syscall.ENOTDIR}
 	}
+	if checks&PathIsFile != 0 && !info.Mode().IsRegular() {
+		return &os.PathError{Op: "check", Path: path, Err: errors.New("not a regular file")}
+	}
+	if checks&PathReadable != 0 {
+		if file, err := os.Open(path); err == nil {
+			file.Close()
+		} else {
+			return err
+		}
+	}
+	return nil
+}
 
+// Parse an octal file mode such as `0640` or `0o2755`. The setuid, setgid and
+// sticky bits are converted to their os.FileMode equivalents.
+func ParseFileMode(s string) (os.FileMode, error) {
 	s = strings.TrimSpace(strings.ToLower(s))
+	s = strings.TrimPrefix(s, "0o")
+	n, err := strconv.ParseUint(s, 8, 32)
+	if err != nil {
+		return 0, err
+	}
+	return getFileMode(n)
+}
+
+// Convert unix permission bits to an os.FileMode.
+func getFileMode(n uint64) (os.FileMode, error) {
+	if n > 07777 {
+		return 0, fmt.Errorf("file mode %#o out of range", n)
+	}
+	mode := os.FileMode(n & 0777)
+	if n&04000 != 0 {
+		mode |= os.ModeSetuid
+	}
+	if n&02000 != 0 {
+		mode |= os.ModeSetgid
+	}
+	if n&01000 != 0 {
+		mode |= os.ModeSticky
+	}
+	return mode, nil
+}
+
+// Resolve a user name or numeric user ID to a user ID.
+func ParseUserID(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if uid, err := strconv.Atoi(s); err == nil {
+		return uid, nil
+	}
+	if u, err := user.Lookup(s); err == nil {
+		return strconv.Atoi(u.Uid)
+	} else {
+		return 0, err
+	}
+}
+
+// Resolve a group name or numeric group ID to a group ID.
+func ParseGroupID(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if gid, err := strconv.Atoi(s); err == nil {
+		return gid, nil
+	}
+	if g, err := user.LookupGroup(s); err == nil {
+		return strconv.Atoi(g.Gid)
+	} else {
+		return 0, err
+	}
+}
+
+// Parse a ratio in the range [0, 1]. Accepts decimals (`0.75`), percentages
+// (`75%`) and fractions (`3/4`).
+func ParseRatio(s string) (float64, error) {
+	str := strings.TrimSpace(s)
+	var ratio float64
+	var err error
+	if strings.HasSuffix(str, "%") {
+		if ratio, err = strconv.ParseFloat(strings.TrimSpace(str[:len(str)-1]), 64); err == nil {
+			ratio /= 100
+		}
+	} else if n := strings.IndexRune(str, '/'); n >= 0 {
+		var num, denom float64
+		if num, err = strconv.ParseFloat(strings.TrimSpace(str[:n]), 64); err == nil {
+			if denom, err = strconv.ParseFloat(strings.TrimSpace(str[n+1:]), 64); err == nil {
+				if denom == 0 {
+					err = fmt.Errorf("invalid ratio %q: zero denominator", s)
+				}
+				ratio = num / denom
+			}
+		}
+	} else {
+		ratio, err = strconv.ParseFloat(str, 64)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return checkRatio(ratio)
+}
+
+// Ensure a ratio lies within [0, 1].
+func checkRatio(ratio float64) (float64, error) {
+	if math.IsNaN(ratio) || ratio < 0 || ratio > 1 {
+		return 0, fmt.Errorf("ratio %v out of range [0, 1]", ratio)
+	}
+	return ratio, nil
+}
+
+var intSuffixes = []struct {
+	symbol string
+	factor int64
+}{
+	{"Ki", 1 << 10},
+	{"Mi", 1 << 20},
+	{"Gi", 1 << 30},
+	{"Ti", 1 << 40},
+	{"k", 1e3},
+	{"K", 1e3},
+	{"M", 1e6},
+	{"G", 1e9},
+	{"T", 1e12},
+}
+
+// Parse a human friendly integer. In addition to plain decimal integers the
+// following forms are accepted:
+//
+//   - Underscores between digits, e.g. `1_000_000`.
+//   - Radix prefixes `0x`, `0o` and `0b`, e.g. `0xff`.
+//   - Magnitude suffixes `k`, `M`, `G` and `T` (powers of 1000) and `Ki`, `Mi`,
+//     `Gi` and `Ti` (powers of 1024), e.g. `10k` or `1.5M`. The result must be
+//     a whole number.
+//
+// Decimal numbers with a leading zero such as `010` are rejected as they are
+// ambiguous with C style octal. So is a lower case `m` suffix which could mean
+// either milli or mega.
+func ParseHumanInt(s string) (int64, error) {
+	str := strings.TrimSpace(s)
+	sign, digits := "", str
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		sign, digits = digits[:1], digits[1:]
+	}
+	if digits == "" {
+		return 0, fmt.Errorf("invalid integer %q", s)
+	}
+
+	lower := strings.ToLower(digits)
+	if strings.HasPrefix(lower, "0x") || strings.HasPrefix(lower, "0o") || strings.HasPrefix(lower, "0b") {
+		if n, err := strconv.ParseInt(sign+digits, 0, 64); err == nil {
+			return n, nil
+		} else {
+			return 0, fmt.Errorf("invalid integer %q: %w", s, err)
+		}
+	}
+
 	var factor int64 = 1
-	for _, size := range sizes {
-		pos := len(s) - len(size.symbol)
-		if s[pos:] == size.symbol {
-			s = strings.TrimSpace(s[:pos])
-			factor = size.factor
+	for _, suffix := range intSuffixes {
+		if strings.HasSuffix(digits, suffix.symbol) {
+			digits = strings.TrimSpace(digits[:len(digits)-len(suffix.symbol)])
+			factor = suffix.factor
 			break
 		}
 	}
+	if strings.HasSuffix(digits, "m") {
+		return 0, fmt.Errorf("ambiguous integer %q: use M for millions", s)
+	}
 
-	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
-		return n * factor, nil
-	} else {
-		return 0, err
+	if strings.HasPrefix(digits, "_") || strings.HasSuffix(digits, "_") || strings.Contains(digits, "__") {
+		return 0, fmt.Errorf("invalid integer %q", s)
+	}
+	digits = strings.Replace(digits, "_", "", -1)
+	if len(digits) > 1 && digits[0] == '0' && digits[1] != '.' {
+		return 0, fmt.Errorf("ambiguous integer %q: leading zero", s)
+	}
+	if digits == "" || strings.Trim(digits, "0123456789.") != "" {
+		return 0, fmt.Errorf("invalid integer %q", s)
+	}
+
+	value, ok := new(big.Rat).SetString(sign + digits)
+	if !ok {
+		return 0, fmt.Errorf("invalid integer %q", s)
+	}
+	value.Mul(value, new(big.Rat).SetInt64(factor))
+	if !value.IsInt() {
+		return 0, fmt.Errorf("invalid integer %q: not a whole number", s)
+	}
+	if n := value.Num(); n.IsInt64() {
+		return n.Int64(), nil
 	}
+	return 0, fmt.Errorf("integer %q overflows int64", s)
 }
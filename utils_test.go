@@ -1,6 +1,8 @@
 package settings
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -33,6 +35,15 @@ func TestParseSize(t *testing.T) {
 		{" 92  Kb ", 94208},
 		{" 92 Kb", 94208},
 		{"92 Kb ", 94208},
+
+		// iec suffixes and fractions
+		{"15.0", 15},
+		{"1.5g", 1610612736},
+		{"2KiB", 2048},
+		{"2 MiB", 2097152},
+		{"0.5ki", 512},
+		{"1.0005k", 1025},
+		{"7e", 7 << 60},
 	}
 
 	for _, test := range tests {
@@ -44,9 +55,15 @@ func TestParseSize(t *testing.T) {
 	}
 
 	errors := []string{
-		"15.0",
+		"",
+		"b",
+		"k",
 		"15 bits",
 		"not a number",
+		"1.2.3k",
+		"1/2k",
+		"8e",
+		"9223372036854775808",
 	}
 
 	for _, str := range errors {
@@ -55,3 +72,253 @@ func TestParseSize(t *testing.T) {
 		}
 	}
 }
+
+func TestParseSizeStrict(t *testing.T) {
+	type test struct {
+		str  string
+		size int64
+	}
+
+	tests := []test{
+		{"32", 32},
+		{"1k", 1000},
+		{"1kB", 1000},
+		{"1KiB", 1024},
+		{"1.5mb", 1500000},
+		{"1.5mib", 1572864},
+		{"2tb", 2000000000000},
+	}
+
+	for _, test := range tests {
+		if b, err := SizeStrict.Parse(test.str); err != nil {
+			t.Errorf("error parsing %s: %s", test.str, err)
+		} else if b != test.size {
+			t.Errorf("size is invalid: %d != %d", b, test.size)
+		}
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	type test struct {
+		mode SizeMode
+		size int64
+		str  string
+	}
+
+	tests := []test{
+		{SizeCompat, 0, "0B"},
+		{SizeCompat, 1000, "1000B"},
+		{SizeCompat, 1024, "1KiB"},
+		{SizeCompat, 1536, "1.5KiB"},
+		{SizeCompat, 1610612736, "1.5GiB"},
+		{SizeCompat, 123456789, "123456789B"},
+		{SizeCompat, -2048, "-2KiB"},
+		{SizeStrict, 1000, "1kB"},
+		{SizeStrict, 1024, "1KiB"},
+		{SizeStrict, 1500000, "1.5MB"},
+	}
+
+	for _, test := range tests {
+		str := test.mode.Format(test.size)
+		if str != test.str {
+			t.Errorf("format is invalid: %s != %s", str, test.str)
+		}
+		if size, err := test.mode.Parse(str); err != nil {
+			t.Errorf("error parsing %s: %s", str, err)
+		} else if size != test.size {
+			t.Errorf("size is invalid: %d != %d", size, test.size)
+		}
+	}
+
+	if str := FormatSize(1 << 20); str != "1MiB" {
+		t.Errorf("format is invalid: %s != %s", str, "1MiB")
+	}
+}
+
+func TestExpandPath(t *testing.T) {
+	type test struct {
+		path string
+		dir  string
+		want string
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+	os.Setenv("GO_SETTINGS_TEST_DIR", "data")
+	defer os.Unsetenv("GO_SETTINGS_TEST_DIR")
+
+	tests := []test{
+		{"/etc/ssl/cert.pem", "/srv/app", "/etc/ssl/cert.pem"},
+		{"cert.pem", "/srv/app", "/srv/app/cert.pem"},
+		{"../certs/cert.pem", "/srv/app", "/srv/certs/cert.pem"},
+		{"cert.pem", "", "cert.pem"},
+		{"~", "/srv/app", home},
+		{"~/cert.pem", "/srv/app", filepath.Join(home, "cert.pem")},
+		{"$GO_SETTINGS_TEST_DIR/db", "/srv/app", "/srv/app/data/db"},
+		{"/var/${GO_SETTINGS_TEST_DIR}", "/srv/app", "/var/data"},
+	}
+
+	for _, test := range tests {
+		if have, err := ExpandPath(test.path, test.dir); err != nil {
+			t.Errorf("error expanding %s: %s", test.path, err)
+		} else if have != test.want {
+			t.Errorf("path is invalid: %s != %s", have, test.want)
+		}
+	}
+
+	if _, err := ExpandPath("~go-settings-no-such-user/x", ""); err == nil {
+		t.Error("no error expanding path for missing user")
+	}
+}
+
+func TestParseFileMode(t *testing.T) {
+	type test struct {
+		str  string
+		mode os.FileMode
+	}
+
+	tests := []test{
+		{"0640", 0640},
+		{"640", 0640},
+		{"0o755", 0755},
+		{" 0600 ", 0600},
+		{"4755", os.ModeSetuid | 0755},
+		{"2775", os.ModeSetgid | 0775},
+		{"1777", os.ModeSticky | 0777},
+	}
+
+	for _, test := range tests {
+		if mode, err := ParseFileMode(test.str); err != nil {
+			t.Errorf("error parsing %s: %s", test.str, err)
+		} else if mode != test.mode {
+			t.Errorf("mode is invalid: %v != %v", mode, test.mode)
+		}
+	}
+
+	errors := []string{"", "0980", "rwxr-xr-x", "17777"}
+	for _, str := range errors {
+		if _, err := ParseFileMode(str); err == nil {
+			t.Errorf("no error parsing %s", str)
+		}
+	}
+}
+
+func TestParseUserID(t *testing.T) {
+	if uid, err := ParseUserID("1234"); err != nil || uid != 1234 {
+		t.Errorf("%d != %d (%v)", uid, 1234, err)
+	}
+	if _, err := ParseUserID("go-settings-no-such-user"); err == nil {
+		t.Error("no error parsing missing user")
+	}
+	if uid, err := ParseUserID("root"); err != nil {
+		t.Skipf("root user not resolvable: %s", err)
+	} else if uid != 0 {
+		t.Errorf("%d != %d", uid, 0)
+	}
+}
+
+func TestParseGroupID(t *testing.T) {
+	if gid, err := ParseGroupID("1234"); err != nil || gid != 1234 {
+		t.Errorf("%d != %d (%v)", gid, 1234, err)
+	}
+	if _, err := ParseGroupID("go-settings-no-such-group"); err == nil {
+		t.Error("no error parsing missing group")
+	}
+}
+
+func TestParseRatio(t *testing.T) {
+	type test struct {
+		str   string
+		ratio float64
+	}
+
+	tests := []test{
+		{"0.75", 0.75},
+		{"75%", 0.75},
+		{" 12.5 % ", 0.125},
+		{"3/4", 0.75},
+		{"1 / 8", 0.125},
+		{"0", 0},
+		{"1", 1},
+		{"100%", 1},
+	}
+
+	for _, test := range tests {
+		if r, err := ParseRatio(test.str); err != nil {
+			t.Errorf("error parsing %s: %s", test.str, err)
+		} else if r != test.ratio {
+			t.Errorf("ratio is invalid: %v != %v", r, test.ratio)
+		}
+	}
+
+	errors := []string{"", "%", "1.5", "-0.1", "150%", "5/4", "1/0", "a/b", "NaN"}
+	for _, str := range errors {
+		if _, err := ParseRatio(str); err == nil {
+			t.Errorf("no error parsing %s", str)
+		}
+	}
+}
+
+func TestParseHumanInt(t *testing.T) {
+	type test struct {
+		str string
+		n   int64
+	}
+
+	tests := []test{
+		{"0", 0},
+		{"42", 42},
+		{"-42", -42},
+		{"1_000_000", 1000000},
+		{"10k", 10000},
+		{"10K", 10000},
+		{"2M", 2000000},
+		{"1.5G", 1500000000},
+		{"3T", 3000000000000},
+		{"4Ki", 4096},
+		{"1 Mi", 1048576},
+		{"0.5k", 500},
+		{"0xff", 255},
+		{"0XFF", 255},
+		{"-0x10", -16},
+		{"0o755", 493},
+		{"0b1010", 10},
+		{"0x_ff_ff", 65535},
+		{" 9223372036854775807 ", 9223372036854775807},
+	}
+
+	for _, test := range tests {
+		if n, err := ParseHumanInt(test.str); err != nil {
+			t.Errorf("error parsing %s: %s", test.str, err)
+		} else if n != test.n {
+			t.Errorf("integer is invalid: %d != %d", n, test.n)
+		}
+	}
+
+	errors := []string{
+		"",
+		"-",
+		"k",
+		"1.5",
+		"0.0001k",
+		"010",
+		"10m",
+		"1__000",
+		"_1000",
+		"1000_",
+		"0xfg",
+		"0b102",
+		"1e6",
+		"9223372036854775808",
+		"10000000T",
+		"ten",
+	}
+
+	for _, str := range errors {
+		if _, err := ParseHumanInt(str); err == nil {
+			t.Errorf("no error parsing %s", str)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package settings
+
+// WalkFunc is called by Walk for each value in the settings object. The path is
+// the key of the value in the key syntax of the settings object. Returning
+// SkipTree from a call on an object or array skips its children. Any other
+// error stops the walk and is returned by Walk.
+type WalkFunc func(path string, value interface{}) error
+
+// Get the value at a key. The empty key refers to the settings object itself.
+func (s *Settings) node(key string) (interface{}, error) {
+	if key == "" {
+		return s.Values, nil
+	}
+	return s.Raw(key)
+}
+
+// Keys returns the sorted names of the children of an object or the indices
+// of an array. The empty key lists the keys of the settings object itself. A
+// TypeError is returned if the value is not an object or array.
+func (s *Settings) Keys(key string) ([]string, error) {
+	value, err := s.node(key)
+	if err != nil {
+		return nil, err
+	}
+	switch value.(type) {
+	case map[interface{}]interface{}, []interface{}:
+		names, _ := getChildren(value)
+		if names == nil {
+			names = []string{}
+		}
+		return names, nil
+	}
+	return nil, s.keyError(key, typeError("object or array", value))
+}
+
+// Len returns the number of children of an object or elements of an array.
+// The empty key refers to the settings object itself. A TypeError is returned
+// if the value is not an object or array.
+func (s *Settings) Len(key string) (int, error) {
+	value, err := s.node(key)
+	if err != nil {
+		return 0, err
+	}
+	switch value.(type) {
+	case map[interface{}]interface{}:
+		return len(value.(map[interface{}]interface{})), nil
+	case []interface{}:
+		return len(value.([]interface{})), nil
+	}
+	return 0, s.keyError(key, typeError("object or array", value))
+}
+
+// Recursively walk the children of a value.
+func (s *Settings) walk(value interface{}, path KeyPath, fn WalkFunc) error {
+	names, children := getChildren(value)
+	for n, child := range children {
+		childPath := path.Append(names[n])
+		if err := fn(s.formatKey(childPath), child); err == SkipTree {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if err := s.walk(child, childPath, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Walk calls `fn` for every value in the settings object in depth first order.
+// Objects and arrays are visited before their children and object keys are
+// visited in sorted order.
+func (s *Settings) Walk(fn WalkFunc) error {
+	return s.walk(s.Values, KeyPath{}, fn)
+}
@@ -0,0 +1,135 @@
+package settings
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func getWalkSettings() *Settings {
+	settings, _ := Parse([]byte(`db:
+  host: localhost
+  port: 5432
+servers:
+- name: web
+- name: api
+empty: {}
+404: page.html
+api.example.com: host
+name: root`))
+	return settings
+}
+
+func TestKeys(t *testing.T) {
+	settings := getWalkSettings()
+
+	tests := map[string][]string{
+		"":        {"404", "api.example.com", "db", "empty", "name", "servers"},
+		"db":      {"host", "port"},
+		"servers": {"0", "1"},
+		"empty":   {},
+	}
+	for key, want := range tests {
+		if value, err := settings.Keys(key); err != nil {
+			t.Errorf("%s: %s", key, err)
+		} else if !reflect.DeepEqual(value, want) {
+			t.Errorf("%s: %v != %v", key, value, want)
+		}
+	}
+
+	if _, err := settings.Keys("name"); !errors.Is(err, TypeError) {
+		t.Errorf("name did not cause a TypeError: %v", err)
+	}
+	if _, err := settings.Keys("missing"); !errors.Is(err, KeyError) {
+		t.Errorf("missing did not cause a KeyError: %v", err)
+	}
+}
+
+func TestLen(t *testing.T) {
+	settings := getWalkSettings()
+
+	tests := map[string]int{"": 6, "db": 2, "servers": 2, "empty": 0}
+	for key, want := range tests {
+		if value, err := settings.Len(key); err != nil {
+			t.Errorf("%s: %s", key, err)
+		} else if value != want {
+			t.Errorf("%s: %v != %v", key, value, want)
+		}
+	}
+
+	if _, err := settings.Len("db.port"); !errors.Is(err, TypeError) {
+		t.Errorf("db.port did not cause a TypeError: %v", err)
+	}
+	if _, err := settings.Len("missing"); !errors.Is(err, KeyError) {
+		t.Errorf("missing did not cause a KeyError: %v", err)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	settings := getWalkSettings()
+
+	var paths []string
+	err := settings.Walk(func(path string, value interface{}) error {
+		paths = append(paths, path)
+		if raw, err := settings.Raw(path); err != nil || !reflect.DeepEqual(raw, value) {
+			t.Errorf("%s: %v != %v (%v)", path, value, raw, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+	want := []string{
+		"404", `"api.example.com"`, "db", "db.host", "db.port", "empty", "name",
+		"servers", "servers.0", "servers.0.name", "servers.1", "servers.1.name",
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("%v != %v", paths, want)
+	}
+
+	// skip subtrees
+	paths = nil
+	settings.Walk(func(path string, value interface{}) error {
+		paths = append(paths, path)
+		if path == "db" || path == "servers.0" || path == "name" {
+			return SkipTree
+		}
+		return nil
+	})
+	want = []string{
+		"404", `"api.example.com"`, "db", "empty", "name",
+		"servers", "servers.0", "servers.1", "servers.1.name",
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("%v != %v", paths, want)
+	}
+
+	// stop on error
+	stop := errors.New("stop")
+	paths = nil
+	err = settings.Walk(func(path string, value interface{}) error {
+		paths = append(paths, path)
+		if path == "db.host" {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Errorf("%v != %v", stop, err)
+	}
+	if want := []string{"404", `"api.example.com"`, "db", "db.host"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("%v != %v", paths, want)
+	}
+
+	// json pointers
+	settings.Pointers = true
+	paths = nil
+	settings.Walk(func(path string, value interface{}) error {
+		paths = append(paths, path)
+		return SkipTree
+	})
+	want = []string{"/404", "/api.example.com", "/db", "/empty", "/name", "/servers"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("%v != %v", paths, want)
+	}
+}